@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
 	"os"
 	"time"
 
@@ -33,6 +34,7 @@ func init() {
 func initConfig() {
 	err := config.LoadConfig()
 	if err != nil {
-		//slg.Logger.Error("there went something wrong while loading config file", "error", err)
+		slg.Logger.Error("there went something wrong while loading config file", "error", err)
+		os.Exit(1)
 	}
 }
@@ -1,18 +1,33 @@
 package config
 
 import (
+	"fmt"
 	"github.com/caarlos0/env/v11"
 	"time"
 )
 
 var AppConfig *Config
 
+const (
+	AuthModeOpaque = "opaque"
+	AuthModeSigned = "signed"
+)
+
+// MaxSignedAccessTokenTTL caps how long a signed token, and the permissions it carries,
+// stays valid without a refresh.
+const MaxSignedAccessTokenTTL = 15 * time.Minute
+
 type Config struct {
-	Server    Server
-	Database  Database
-	CTX       CTX
-	RateLimit RateLimit
-	SMTP      SMTP
+	Server     Server
+	Database   Database
+	CTX        CTX
+	RateLimit  RateLimit
+	SMTP       SMTP
+	Pagination Pagination
+	Token      Token
+	Auth       Auth
+	Lockout    Lockout
+	Account    Account
 }
 
 type Server struct {
@@ -60,6 +75,58 @@ type SMTP struct {
 	Sender   string `env:"SMTP_SENDER"`
 }
 
+// Pagination holds the secret that signs movie listing cursors. It must be at least 32
+// bytes; until it is set, requests in cursor mode fail and offset pagination is unaffected.
+type Pagination struct {
+	CursorSecret string `env:"CURSOR_SECRET"`
+}
+
+type Token struct {
+	ActivationTTL     time.Duration `env:"TOKEN_ACTIVATION_TTL" envDefault:"72h"`
+	AuthenticationTTL time.Duration `env:"TOKEN_AUTHENTICATION_TTL" envDefault:"24h"`
+	RefreshTTL        time.Duration `env:"TOKEN_REFRESH_TTL" envDefault:"720h"`
+	PasswordResetTTL  time.Duration `env:"TOKEN_PASSWORD_RESET_TTL" envDefault:"45m"`
+	TwoFactorTTL      time.Duration `env:"TOKEN_TWO_FACTOR_TTL" envDefault:"5m"`
+	LoginTTL          time.Duration `env:"TOKEN_LOGIN_TTL" envDefault:"15m"`
+	EmailChangeTTL    time.Duration `env:"TOKEN_EMAIL_CHANGE_TTL" envDefault:"24h"`
+}
+
+// Auth selects how authentication tokens are issued. In signed mode they are short-lived
+// tokens verified without a database lookup; SigningKeys holds "kid:secret" pairs.
+// PermissionCacheTTL bounds how long a user's permissions are reused before reloading.
+type Auth struct {
+	Mode           string        `env:"AUTH_MODE" envDefault:"opaque"`
+	SigningKeys    []string      `env:"AUTH_SIGNING_KEYS" envSeparator:","`
+	ActiveKeyID    string        `env:"AUTH_ACTIVE_KEY_ID"`
+	AccessTokenTTL time.Duration `env:"AUTH_ACCESS_TOKEN_TTL" envDefault:"15m"`
+	TOTPIssuer     string        `env:"AUTH_TOTP_ISSUER" envDefault:"Cinemaniac"`
+	MagicLinkURL   string        `env:"AUTH_MAGIC_LINK_URL" envDefault:"http://localhost:3000/login"`
+	DefaultRole    string        `env:"AUTH_DEFAULT_ROLE" envDefault:"member"`
+
+	PermissionCacheTTL time.Duration `env:"AUTH_PERMISSION_CACHE_TTL" envDefault:"1m"`
+}
+
+// Lockout throttles password sign-in. Once an account or IP has BackoffAfter recent
+// failures each further failure blocks it for exponentially longer, up to BackoffMax;
+// at MaxFailures (or IPMaxFailures) it is locked for LockDuration. Failures older than
+// FailureWindow are forgotten.
+type Lockout struct {
+	BackoffAfter  int           `env:"LOCKOUT_BACKOFF_AFTER" envDefault:"3"`
+	BackoffBase   time.Duration `env:"LOCKOUT_BACKOFF_BASE" envDefault:"1s"`
+	BackoffMax    time.Duration `env:"LOCKOUT_BACKOFF_MAX" envDefault:"2m"`
+	MaxFailures   int           `env:"LOCKOUT_MAX_FAILURES" envDefault:"10"`
+	IPMaxFailures int           `env:"LOCKOUT_IP_MAX_FAILURES" envDefault:"50"`
+	LockDuration  time.Duration `env:"LOCKOUT_LOCK_DURATION" envDefault:"30m"`
+	FailureWindow time.Duration `env:"LOCKOUT_FAILURE_WINDOW" envDefault:"15m"`
+}
+
+// Account controls deletion: a deleted account is kept for DeletionGracePeriod, and a
+// background job checks for accounts past that point every PurgeInterval.
+type Account struct {
+	DeletionGracePeriod time.Duration `env:"ACCOUNT_DELETION_GRACE_PERIOD" envDefault:"720h"`
+	PurgeInterval       time.Duration `env:"ACCOUNT_PURGE_INTERVAL" envDefault:"1h"`
+}
+
 func LoadConfig() error {
 	config := &Config{}
 
@@ -67,6 +134,16 @@ func LoadConfig() error {
 		return err
 	}
 
+	switch config.Auth.Mode {
+	case AuthModeOpaque:
+	case AuthModeSigned:
+		if config.Auth.AccessTokenTTL > MaxSignedAccessTokenTTL {
+			return fmt.Errorf("AUTH_ACCESS_TOKEN_TTL must not be more than %s in signed mode", MaxSignedAccessTokenTTL)
+		}
+	default:
+		return fmt.Errorf("AUTH_MODE must be %q or %q, not %q", AuthModeOpaque, AuthModeSigned, config.Auth.Mode)
+	}
+
 	AppConfig = config
 
 	return nil
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// AccountExport is everything stored about a user, as handed to them on request.
+type AccountExport struct {
+	ExportedAt  time.Time        `json:"exported_at"`
+	User        *User            `json:"user"`
+	Permissions Permissions      `json:"permissions"`
+	Sessions    []*Session       `json:"sessions"`
+	ApiKeys     []*ApiKey        `json:"api_keys"`
+	Ratings     []*Rating        `json:"ratings"`
+	Reviews     []*Review        `json:"reviews"`
+	Lists       []*List          `json:"lists"`
+	History     []*HistoryEntry  `json:"watch_history"`
+	Deletion    *AccountDeletion `json:"deletion,omitempty"`
+}
+
+// AccountDeletion is a pending request to delete an account. The account is kept, and the
+// request can be cancelled, until PurgeAfter.
+type AccountDeletion struct {
+	UserID      int64     `json:"-"`
+	RequestedAt time.Time `json:"requested_at"`
+	PurgeAfter  time.Time `json:"purge_after"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"time"
+)
+
+// ApiKey is a long-lived credential for non-interactive clients. A nil Permissions grants
+// everything its owner is allowed to do; otherwise the key is limited to those codes.
+type ApiKey struct {
+	ID          int64      `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UserID      int64      `json:"-"`
+	Name        string     `json:"name"`
+	Plaintext   string     `json:"key,omitzero"`
+	Hash        []byte     `json:"-"`
+	Permissions []string   `json:"permissions"`
+	Expiry      *time.Time `json:"expiry,omitzero"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitzero"`
+}
+
+func ValidateApiKey(v *validator.Validator, key *ApiKey, userPermissions Permissions) {
+	v.Check(key.Name != "", "name", "must be provided")
+	v.Check(len(key.Name) <= 100, "name", "must not be more than 100 bytes long")
+
+	if key.Permissions != nil {
+		v.Check(validator.Unique(key.Permissions), "permissions", "must not contain duplicate values")
+		for _, code := range key.Permissions {
+			v.Check(userPermissions.Include(code), "permissions", "must only contain permissions you have")
+		}
+	}
+
+	if key.Expiry != nil {
+		v.Check(key.Expiry.After(time.Now()), "expiry", "must be in the future")
+	}
+}
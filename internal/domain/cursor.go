@@ -0,0 +1,30 @@
+package domain
+
+import "strconv"
+
+type Cursor struct {
+	Sort     string `json:"s"`
+	Value    string `json:"v"`
+	ID       int64  `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+type CursorMetadata struct {
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitzero"`
+	PrevCursor string `json:"prev_cursor,omitzero"`
+}
+
+// MovieSortValue returns the value of the given sort column for movie, as stored in a cursor.
+func MovieSortValue(movie *Movie, column string) string {
+	switch column {
+	case "title":
+		return movie.Title
+	case "year":
+		return strconv.Itoa(int(movie.Year))
+	case "runtime":
+		return strconv.Itoa(int(movie.Runtime))
+	default:
+		return strconv.FormatInt(movie.ID, 10)
+	}
+}
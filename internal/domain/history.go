@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"time"
+)
+
+type HistoryEntry struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"-"`
+	MovieID   int64     `json:"movie_id"`
+	Title     string    `json:"title,omitzero"`
+	Year      int32     `json:"year,omitzero"`
+	Runtime   int32     `json:"runtime,omitzero"`
+	WatchedAt time.Time `json:"watched_at"`
+	Rating    int32     `json:"rating,omitzero"`
+}
+
+type GenreCount struct {
+	Genre string `json:"genre"`
+	Count int    `json:"count"`
+}
+
+type YearCount struct {
+	Year  int `json:"year"`
+	Count int `json:"count"`
+}
+
+type WatchStats struct {
+	TotalWatched  int          `json:"total_watched"`
+	TotalRuntime  int64        `json:"total_runtime"`
+	TopGenres     []GenreCount `json:"top_genres"`
+	MoviesPerYear []YearCount  `json:"movies_per_year"`
+}
+
+func ValidateHistoryEntry(v *validator.Validator, entry *HistoryEntry) {
+	v.Check(entry.MovieID > 0, "movie_id", "must be provided")
+	v.Check(!entry.WatchedAt.After(time.Now()), "watched_at", "must not be in the future")
+	v.Check(entry.Rating == 0 || (entry.Rating >= 1 && entry.Rating <= 10), "rating", "must be between 1 and 10")
+}
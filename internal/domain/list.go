@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"time"
+)
+
+const (
+	VisibilityPrivate  = "private"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPublic   = "public"
+)
+
+type List struct {
+	ID          int64       `json:"id"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	UserID      int64       `json:"user_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitzero"`
+	Visibility  string      `json:"visibility"`
+	Slug        string      `json:"slug"`
+	Version     int32       `json:"version"`
+	Items       []*ListItem `json:"items,omitzero"`
+}
+
+type ListItem struct {
+	MovieID  int64     `json:"movie_id"`
+	Title    string    `json:"title"`
+	Year     int32     `json:"year"`
+	Position int32     `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+// VisibleTo reports whether user may see the list when it is looked up by its slug.
+func (l *List) VisibleTo(user *User) bool {
+	return l.Visibility != VisibilityPrivate || l.UserID == user.ID
+}
+
+func ValidateList(v *validator.Validator, list *List) {
+	v.Check(list.Name != "", "name", "must be provided")
+	v.Check(len(list.Name) <= 200, "name", "must not be more than 200 bytes long")
+
+	v.Check(len(list.Description) <= 2000, "description", "must not be more than 2000 bytes long")
+
+	v.Check(validator.PermittedValue(list.Visibility, VisibilityPrivate, VisibilityUnlisted, VisibilityPublic), "visibility", "invalid visibility")
+}
+
+// ValidateListOrder checks that movieIDs is a reordering of exactly the movies in items.
+func ValidateListOrder(v *validator.Validator, items []*ListItem, movieIDs []int64) {
+	v.Check(validator.Unique(movieIDs), "movie_ids", "must not contain duplicate values")
+	v.Check(len(movieIDs) == len(items), "movie_ids", "must contain every movie in the list")
+
+	current := make(map[int64]bool, len(items))
+	for _, item := range items {
+		current[item.MovieID] = true
+	}
+
+	for _, id := range movieIDs {
+		v.Check(current[id], "movie_ids", "must only contain movies in the list")
+	}
+}
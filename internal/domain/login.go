@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// LoginAttempts tracks recent failed sign-ins for one account or one client IP.
+type LoginAttempts struct {
+	Key          string
+	Failures     int
+	BlockedUntil *time.Time
+	Locked       bool
+}
+
+func LoginAccountKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func LoginIPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// RetryAfter reports how long sign-in stays blocked, or zero if it is not.
+func (l *LoginAttempts) RetryAfter() time.Duration {
+	if l.BlockedUntil == nil {
+		return 0
+	}
+
+	return max(time.Until(*l.BlockedUntil), 0)
+}
@@ -5,14 +5,34 @@ import (
 	"time"
 )
 
+const (
+	SearchModePlain     = "plain"
+	SearchModeWebsearch = "websearch"
+)
+
 type Movie struct {
-	ID        int64     `json:"id"`
-	CreatedAt time.Time `json:"-"`
-	Title     string    `json:"title"`
-	Year      int32     `json:"year,omitzero"`
-	Runtime   int32     `json:"runtime,omitzero"`
-	Genres    []string  `json:"genres,omitzero"`
-	Version   int32     `json:"version"`
+	ID            int64     `json:"id"`
+	CreatedAt     time.Time `json:"-"`
+	Title         string    `json:"title"`
+	Year          int32     `json:"year,omitzero"`
+	Runtime       int32     `json:"runtime,omitzero"`
+	Genres        []string  `json:"genres,omitzero"`
+	RatingAverage float64   `json:"rating_average"`
+	RatingCount   int32     `json:"rating_count"`
+	Version       int32     `json:"version"`
+
+	// CreatedBy is the user who added the movie, if they still exist.
+	CreatedBy *int64 `json:"-"`
+}
+
+// EditableBy reports whether the user may change or delete the movie: anyone with
+// movies:write may, and those with only movies:write:own may for movies they created.
+func (m *Movie) EditableBy(user *User, permissions Permissions) bool {
+	if permissions.Include("movies:write") {
+		return true
+	}
+
+	return permissions.Include("movies:write:own") && m.CreatedBy != nil && *m.CreatedBy == user.ID
 }
 
 func ValidateMovie(v *validator.Validator, movie *Movie) {
@@ -31,3 +51,15 @@ func ValidateMovie(v *validator.Validator, movie *Movie) {
 	v.Check(len(movie.Genres) <= 5, "genres", "must not contain more than 5 genres")
 	v.Check(validator.Unique(movie.Genres), "genres", "must not contain duplicate values")
 }
+
+type MovieSearchResult struct {
+	*Movie
+	Rank     float32 `json:"rank"`
+	Headline string  `json:"headline"`
+}
+
+func ValidateMovieSearch(v *validator.Validator, query, mode string) {
+	v.Check(query != "", "q", "must be provided")
+	v.Check(len(query) <= 500, "q", "must not be more than 500 bytes long")
+	v.Check(validator.PermittedValue(mode, SearchModePlain, SearchModeWebsearch), "search_mode", "invalid search mode")
+}
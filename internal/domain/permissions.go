@@ -1,9 +1,144 @@
 package domain
 
-import "slices"
+import (
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	PermissionActionCreate = "create"
+	PermissionActionGrant  = "grant"
+	PermissionActionRevoke = "revoke"
+
+	PermissionActionCreateRole = "create_role"
+	PermissionActionAssign     = "assign"
+	PermissionActionUnassign   = "unassign"
+)
+
+// PermissionWildcard on its own grants every permission; as the last segment of a code,
+// as in movies:*, it grants every code under that prefix.
+const PermissionWildcard = "*"
+
+// impliedActions lists, for a code segment, the segments it also grants in the same
+// position, so that movies:write covers movies:read.
+var impliedActions = map[string][]string{
+	"write": {"read"},
+}
+
+var (
+	PermissionCodeRX = regexp.MustCompile(`^(\*|[a-z][a-z_]*(:[a-z][a-z_]*)*:\*|[a-z][a-z_]*(:[a-z][a-z_]*)+)$`)
+	RoleNameRX       = regexp.MustCompile(`^[a-z][a-z_-]*$`)
+)
 
 type Permissions []string
 
+type Permission struct {
+	ID   int64  `json:"id"`
+	Code string `json:"code"`
+}
+
+// Role is a named bundle of permissions. Users assigned a role have all of its permissions
+// on top of those granted to them directly.
+type Role struct {
+	ID          int64       `json:"id"`
+	Name        string      `json:"name"`
+	Permissions Permissions `json:"permissions"`
+}
+
+// PermissionAuditEntry records one change made through the permission administration API.
+// Code and Role are empty when the action does not concern one. ActorID and UserID are
+// cleared if those users are later deleted.
+type PermissionAuditEntry struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ActorID   *int64    `json:"actor_id"`
+	Action    string    `json:"action"`
+	Code      string    `json:"code,omitzero"`
+	Role      string    `json:"role,omitzero"`
+	UserID    *int64    `json:"user_id,omitempty"`
+}
+
+// Include reports whether any of the permissions grants code.
 func (p Permissions) Include(code string) bool {
-	return slices.Contains(p, code)
+	for _, held := range p {
+		if permissionGrants(held, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// Restrict returns permissions granting only what is granted both by p and by limit. Codes
+// are kept in whichever form is narrower, so a user holding * restricted to movies:read
+// gets movies:read.
+func (p Permissions) Restrict(limit []string) Permissions {
+	restricted := Permissions{}
+	for _, code := range limit {
+		if p.Include(code) && !slices.Contains(restricted, code) {
+			restricted = append(restricted, code)
+		}
+	}
+	for _, code := range p {
+		if Permissions(limit).Include(code) && !slices.Contains(restricted, code) {
+			restricted = append(restricted, code)
+		}
+	}
+	return restricted
+}
+
+// permissionGrants reports whether holding the held code grants the required one. Codes
+// are compared segment by segment: a segment grants itself and the actions it implies, a
+// trailing * grants anything below it, and a code grants every more specific code it is a
+// prefix of, so movies:write also grants movies:write:own.
+func permissionGrants(held, required string) bool {
+	if held == PermissionWildcard {
+		return true
+	}
+
+	heldSegments := strings.Split(held, ":")
+	requiredSegments := strings.Split(required, ":")
+
+	for i, segment := range heldSegments {
+		if segment == PermissionWildcard && i == len(heldSegments)-1 {
+			return len(requiredSegments) > i
+		}
+
+		if i >= len(requiredSegments) {
+			return false
+		}
+
+		if segment != requiredSegments[i] && !slices.Contains(impliedActions[segment], requiredSegments[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func ValidatePermissionCode(v *validator.Validator, code string) {
+	v.Check(code != "", "code", "must be provided")
+	v.Check(len(code) <= 100, "code", "must not be more than 100 bytes long")
+	v.Check(validator.Matches(code, PermissionCodeRX), "code", "must be lowercase words separated by colons, such as movies:write or movies:*")
+}
+
+func ValidatePermissionCodes(v *validator.Validator, codes []string) {
+	v.Check(len(codes) > 0, "codes", "must contain at least 1 code")
+	v.Check(validator.Unique(codes), "codes", "must not contain duplicate values")
+	for _, code := range codes {
+		v.Check(validator.Matches(code, PermissionCodeRX), "codes", "must only contain valid permission codes")
+	}
+}
+
+func ValidateRole(v *validator.Validator, role *Role) {
+	v.Check(role.Name != "", "name", "must be provided")
+	v.Check(len(role.Name) <= 100, "name", "must not be more than 100 bytes long")
+	v.Check(validator.Matches(role.Name, RoleNameRX), "name", "must be lowercase letters, hyphens or underscores")
+
+	v.Check(validator.Unique(role.Permissions), "codes", "must not contain duplicate values")
+	for _, code := range role.Permissions {
+		v.Check(validator.Matches(code, PermissionCodeRX), "codes", "must only contain valid permission codes")
+	}
 }
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"slices"
+	"testing"
+)
+
+func TestPermissionsInclude(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions Permissions
+		code        string
+		want        bool
+	}{
+		{"exact match", Permissions{"movies:read"}, "movies:read", true},
+		{"no permissions", Permissions{}, "movies:read", false},
+		{"nil permissions", nil, "movies:read", false},
+		{"different resource", Permissions{"movies:read"}, "ratings:read", false},
+		{"different action", Permissions{"movies:read"}, "movies:write", false},
+		{"superuser", Permissions{"*"}, "movies:write", true},
+		{"superuser deeper code", Permissions{"*"}, "movies:write:own", true},
+		{"superuser wildcard code", Permissions{"*"}, "movies:*", true},
+		{"resource wildcard", Permissions{"movies:*"}, "movies:write", true},
+		{"resource wildcard deeper code", Permissions{"movies:*"}, "movies:write:own", true},
+		{"resource wildcard itself", Permissions{"movies:*"}, "movies:*", true},
+		{"resource wildcard other resource", Permissions{"movies:*"}, "reviews:write", false},
+		{"resource wildcard needs a segment", Permissions{"movies:*"}, "movies", false},
+		{"resource wildcard is not a prefix match", Permissions{"movies:*"}, "moviesx:read", false},
+		{"resource wildcard does not grant superuser", Permissions{"movies:*"}, "*", false},
+		{"write implies read", Permissions{"movies:write"}, "movies:read", true},
+		{"read does not imply write", Permissions{"movies:read"}, "movies:write", false},
+		{"write implies read of its resource only", Permissions{"movies:write"}, "ratings:read", false},
+		{"write implies own", Permissions{"movies:write"}, "movies:write:own", true},
+		{"write implies read own", Permissions{"movies:write"}, "movies:read:own", true},
+		{"own does not imply write", Permissions{"movies:write:own"}, "movies:write", false},
+		{"own does not imply other owner scope", Permissions{"movies:write:own"}, "movies:write:all", false},
+		{"narrow code does not grant wildcard", Permissions{"movies:read"}, "movies:*", false},
+		{"write does not grant wildcard", Permissions{"movies:write"}, "movies:*", false},
+		{"wildcard only in last segment", Permissions{"*:read"}, "movies:read", false},
+		{"any of several", Permissions{"ratings:write", "movies:read"}, "movies:read", true},
+		{"admin is not implied", Permissions{"users:write"}, "users:admin", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.permissions.Include(tt.code); got != tt.want {
+				t.Errorf("%v.Include(%q) = %t; want %t", tt.permissions, tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionsRestrict(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions Permissions
+		limit       []string
+		want        Permissions
+	}{
+		{"exact intersection", Permissions{"movies:read", "ratings:write"}, []string{"movies:read"}, Permissions{"movies:read"}},
+		{"empty limit", Permissions{"movies:read"}, []string{}, Permissions{}},
+		{"limit beyond permissions", Permissions{"movies:read"}, []string{"reviews:write"}, Permissions{}},
+		{"limit implying permissions", Permissions{"movies:read"}, []string{"movies:write"}, Permissions{"movies:read"}},
+		{"superuser narrowed", Permissions{"*"}, []string{"movies:read"}, Permissions{"movies:read"}},
+		{"wildcard narrowed", Permissions{"movies:*"}, []string{"movies:write", "reviews:write"}, Permissions{"movies:write"}},
+		{"wildcard limit", Permissions{"movies:read", "ratings:write"}, []string{"movies:*"}, Permissions{"movies:read"}},
+		{"implied limit", Permissions{"movies:write"}, []string{"movies:read"}, Permissions{"movies:read"}},
+		{"no duplicates", Permissions{"movies:read"}, []string{"movies:read", "movies:*"}, Permissions{"movies:read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.permissions.Restrict(tt.limit)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("%v.Restrict(%v) = %v; want %v", tt.permissions, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePermissionCode(t *testing.T) {
+	tests := []struct {
+		code  string
+		valid bool
+	}{
+		{"movies:read", true},
+		{"movies:write:own", true},
+		{"users_admin:read", true},
+		{"movies:*", true},
+		{"movies:write:*", true},
+		{"*", true},
+		{"", false},
+		{"movies", false},
+		{"Movies:read", false},
+		{"movies:", false},
+		{":read", false},
+		{"movies::read", false},
+		{"*:read", false},
+		{"movies:*:own", false},
+		{"movies:**", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			v := validator.New()
+			ValidatePermissionCode(v, tt.code)
+			if v.Valid() != tt.valid {
+				t.Errorf("ValidatePermissionCode(%q) valid = %t; want %t", tt.code, v.Valid(), tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"time"
+)
+
+const (
+	RoleActor           = "actor"
+	RoleDirector        = "director"
+	RoleWriter          = "writer"
+	RoleProducer        = "producer"
+	RoleComposer        = "composer"
+	RoleCinematographer = "cinematographer"
+	RoleEditor          = "editor"
+)
+
+type Person struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"-"`
+	Name      string    `json:"name"`
+	Biography string    `json:"biography,omitzero"`
+	Version   int32     `json:"version"`
+}
+
+type Credit struct {
+	ID            int64  `json:"id"`
+	MovieID       int64  `json:"movie_id"`
+	MovieTitle    string `json:"movie_title,omitzero"`
+	MovieYear     int32  `json:"movie_year,omitzero"`
+	PersonID      int64  `json:"person_id"`
+	PersonName    string `json:"person_name,omitzero"`
+	Role          string `json:"role"`
+	CharacterName string `json:"character_name,omitzero"`
+	BillingOrder  int32  `json:"billing_order"`
+}
+
+func ValidatePerson(v *validator.Validator, person *Person) {
+	v.Check(person.Name != "", "name", "must be provided")
+	v.Check(len(person.Name) <= 500, "name", "must not be more than 500 bytes long")
+
+	v.Check(len(person.Biography) <= 10_000, "biography", "must not be more than 10000 bytes long")
+}
+
+func ValidateCredit(v *validator.Validator, credit *Credit) {
+	v.Check(credit.PersonID > 0, "person_id", "must be provided")
+
+	v.Check(credit.Role != "", "role", "must be provided")
+	v.Check(validator.PermittedValue(credit.Role, RoleActor, RoleDirector, RoleWriter, RoleProducer, RoleComposer, RoleCinematographer, RoleEditor), "role", "invalid role")
+
+	v.Check(len(credit.CharacterName) <= 500, "character_name", "must not be more than 500 bytes long")
+	v.Check(credit.Role == RoleActor || credit.CharacterName == "", "character_name", "must only be provided for actors")
+
+	v.Check(credit.BillingOrder >= 0, "billing_order", "must not be negative")
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"time"
+)
+
+type Rating struct {
+	UserID    int64     `json:"-"`
+	MovieID   int64     `json:"movie_id"`
+	Score     int32     `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ValidateRating(v *validator.Validator, rating *Rating) {
+	v.Check(rating.Score != 0, "score", "must be provided")
+	v.Check(rating.Score >= 1 && rating.Score <= 10, "score", "must be between 1 and 10")
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"time"
+)
+
+const (
+	ReviewStatusPending   = "pending"
+	ReviewStatusPublished = "published"
+	ReviewStatusRejected  = "rejected"
+)
+
+type Review struct {
+	ID               int64      `json:"id"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	MovieID          int64      `json:"movie_id"`
+	UserID           int64      `json:"user_id"`
+	UserName         string     `json:"user_name,omitzero"`
+	Title            string     `json:"title"`
+	Body             string     `json:"body"`
+	Status           string     `json:"status"`
+	ModerationReason string     `json:"moderation_reason,omitzero"`
+	ModeratedBy      *int64     `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time `json:"moderated_at,omitempty"`
+	Version          int32      `json:"version"`
+}
+
+func ValidateReview(v *validator.Validator, review *Review) {
+	v.Check(review.Title != "", "title", "must be provided")
+	v.Check(len(review.Title) <= 200, "title", "must not be more than 200 bytes long")
+
+	v.Check(review.Body != "", "body", "must be provided")
+	v.Check(len(review.Body) <= 20_000, "body", "must not be more than 20000 bytes long")
+}
+
+func ValidateModeration(v *validator.Validator, status, reason string) {
+	v.Check(validator.PermittedValue(status, ReviewStatusPublished, ReviewStatusRejected), "status", "invalid status")
+	v.Check(status != ReviewStatusRejected || reason != "", "reason", "must be provided when rejecting a review")
+	v.Check(len(reason) <= 1000, "reason", "must not be more than 1000 bytes long")
+}
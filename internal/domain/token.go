@@ -6,8 +6,13 @@ import (
 )
 
 const (
-	ScopeActivation     = "activation"
-	ScopeAuthentication = "authentication"
+	ScopeActivation       = "activation"
+	ScopeAuthentication   = "authentication"
+	ScopePasswordReset    = "password-reset"
+	ScopeRefresh          = "refresh"
+	ScopeTwoFactorPending = "2fa-pending"
+	ScopeLogin            = "login"
+	ScopeEmailChange      = "email-change"
 )
 
 type Token struct {
@@ -16,6 +21,35 @@ type Token struct {
 	UserID    int64     `json:"-"`
 	Expiry    time.Time `json:"expiry"`
 	Scope     string    `json:"-"`
+	IP        string    `json:"-"`
+	UserAgent string    `json:"-"`
+	Family    string    `json:"-"`
+	Rotated   bool      `json:"-"`
+
+	// Payload carries data the token applies when used, such as the new address of an
+	// email change.
+	Payload string `json:"-"`
+}
+
+// AuthenticationTokens is the pair handed out on sign-in: a short-lived token for API
+// calls and a long-lived one that can be exchanged for a new pair.
+type AuthenticationTokens struct {
+	Authentication *Token `json:"authentication_token"`
+	Refresh        *Token `json:"refresh_token"`
+
+	// TwoFactorPending is set instead of the other two when the account has 2FA enabled.
+	TwoFactorPending *Token `json:"two_factor_token,omitzero"`
+}
+
+// Session describes an authentication token as its owner sees it in the sessions list.
+type Session struct {
+	ID         int64     `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Expiry     time.Time `json:"expiry"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Current    bool      `json:"current"`
 }
 
 func ValidateTokenPlaintext(v *validator.Validator, tokenPlaintext string) {
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"regexp"
+	"time"
+)
+
+var TOTPCodeRX = regexp.MustCompile(`^[0-9]{6}$`)
+
+// TwoFactor holds a user's TOTP secret. It only protects sign-in once Confirmed is set.
+type TwoFactor struct {
+	UserID       int64
+	CreatedAt    time.Time
+	Secret       string
+	Confirmed    bool
+	LastUsedStep int64
+}
+
+// TwoFactorEnrollment is returned once, when the user starts enrolling an authenticator app.
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+func ValidateTOTPCode(v *validator.Validator, code string) {
+	v.Check(code != "", "code", "must be provided")
+	v.Check(validator.Matches(code, TOTPCodeRX), "code", "must be a 6 digit code")
+}
+
+// ValidateTwoFactorCode accepts either a TOTP code or a recovery code.
+func ValidateTwoFactorCode(v *validator.Validator, code string) {
+	v.Check(code != "", "code", "must be provided")
+	v.Check(len(code) <= 32, "code", "must not be more than 32 bytes long")
+}
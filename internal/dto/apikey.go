@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type ApiKey struct {
+	Name        string     `json:"name"`
+	Permissions []string   `json:"permissions"`
+	Expiry      *time.Time `json:"expiry"`
+}
+
+type UpdateApiKey struct {
+	Name        *string    `json:"name"`
+	Permissions *[]string  `json:"permissions"`
+	Expiry      *time.Time `json:"expiry"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type HistoryEntry struct {
+	MovieID   int64      `json:"movie_id"`
+	WatchedAt *time.Time `json:"watched_at"`
+	Rating    int32      `json:"rating"`
+}
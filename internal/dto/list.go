@@ -0,0 +1,21 @@
+package dto
+
+type List struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+}
+
+type UpdateList struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Visibility  *string `json:"visibility"`
+}
+
+type ListItem struct {
+	MovieID int64 `json:"movie_id"`
+}
+
+type ReorderList struct {
+	MovieIDs []int64 `json:"movie_ids"`
+}
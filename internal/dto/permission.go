@@ -0,0 +1,18 @@
+package dto
+
+type Permission struct {
+	Code string `json:"code"`
+}
+
+type PermissionCodes struct {
+	Codes []string `json:"codes"`
+}
+
+type Role struct {
+	Name  string   `json:"name"`
+	Codes []string `json:"codes"`
+}
+
+type RoleAssignment struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+type Person struct {
+	Name      string `json:"name"`
+	Biography string `json:"biography"`
+}
+
+type UpdatePerson struct {
+	Name      *string `json:"name"`
+	Biography *string `json:"biography"`
+}
+
+type Credit struct {
+	PersonID      int64  `json:"person_id"`
+	Role          string `json:"role"`
+	CharacterName string `json:"character_name"`
+	BillingOrder  int32  `json:"billing_order"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type Rating struct {
+	Score int32 `json:"score"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+type Review struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type UpdateReview struct {
+	Title *string `json:"title"`
+	Body  *string `json:"body"`
+}
+
+type ModerateReview struct {
+	Reason string `json:"reason"`
+}
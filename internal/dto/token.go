@@ -1,6 +1,24 @@
 package dto
 
 type Token struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type EmailTokenRequest struct {
+	Email string `json:"email"`
+}
+
+type RefreshTokenRequest struct {
+	TokenPlaintext string `json:"refresh_token"`
+	IP             string `json:"-"`
+	UserAgent      string `json:"-"`
+}
+
+type MagicLinkLogin struct {
+	TokenPlaintext string `json:"token"`
+	IP             string `json:"-"`
+	UserAgent      string `json:"-"`
 }
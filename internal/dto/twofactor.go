@@ -0,0 +1,17 @@
+package dto
+
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorLogin struct {
+	TokenPlaintext string `json:"token"`
+	Code           string `json:"code"`
+	IP             string `json:"-"`
+	UserAgent      string `json:"-"`
+}
+
+type DisableTwoFactor struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
@@ -9,3 +9,30 @@ type User struct {
 type ActivateUserRequest struct {
 	TokenPlaintext string `json:"token"`
 }
+
+type ResetPasswordRequest struct {
+	Password       string `json:"password"`
+	TokenPlaintext string `json:"token"`
+}
+
+type UpdateProfile struct {
+	Name *string `json:"name"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password"`
+	Password        string `json:"password"`
+}
+
+type ChangeEmail struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type ConfirmEmailChange struct {
+	TokenPlaintext string `json:"token"`
+}
+
+type DeleteAccount struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"net/http"
+)
+
+type ApiKeyHandler struct {
+	apiKeyService service.ApiKeyService
+}
+
+func (a *ApiKeyHandler) CreateApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.ApiKey
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	key, err := a.apiKeyService.CreateApiKey(r.Context(), ContextGetUser(r), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		if errors.As(err, &valErr) {
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+			return
+		}
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/me/api-keys/%d", key.ID))
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"api_key": key}, headers); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (a *ApiKeyHandler) GetApiKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := a.apiKeyService.GetApiKeys(r.Context(), ContextGetUser(r))
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"api_keys": keys}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (a *ApiKeyHandler) ShowApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	key, err := a.apiKeyService.GetApiKey(r.Context(), ContextGetUser(r), id)
+	a.writeApiKey(w, r, key, err)
+}
+
+func (a *ApiKeyHandler) UpdateApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var input dto.UpdateApiKey
+	if err := helper.ReadJSON(w, r, &input); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	key, err := a.apiKeyService.UpdateApiKey(r.Context(), ContextGetUser(r), id, &input)
+	a.writeApiKey(w, r, key, err)
+}
+
+func (a *ApiKeyHandler) DeleteApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = a.apiKeyService.DeleteApiKey(r.Context(), ContextGetUser(r), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "api key successfully deleted"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (a *ApiKeyHandler) writeApiKey(w http.ResponseWriter, r *http.Request, key *domain.ApiKey, err error) {
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"api_key": key}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewApiKeyHandler(apiKeyService service.ApiKeyService) *ApiKeyHandler {
+	return &ApiKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
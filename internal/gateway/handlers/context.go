@@ -8,7 +8,12 @@ import (
 
 type contextKey string
 
-const userContextKey = contextKey("user")
+const (
+	userContextKey        = contextKey("user")
+	tokenContextKey       = contextKey("token")
+	permissionsContextKey = contextKey("permissions")
+	apiKeyContextKey      = contextKey("apiKey")
+)
 
 func ContextSetUser(r *http.Request, user *domain.User) *http.Request {
 	ctx := context.WithValue(r.Context(), userContextKey, user)
@@ -22,3 +27,47 @@ func ContextGetUser(r *http.Request) *domain.User {
 	}
 	return user
 }
+
+// ContextSetToken records the bearer token the request was authenticated with.
+func ContextSetToken(r *http.Request, token string) *http.Request {
+	ctx := context.WithValue(r.Context(), tokenContextKey, token)
+	return r.WithContext(ctx)
+}
+
+func ContextGetToken(r *http.Request) string {
+	token, _ := r.Context().Value(tokenContextKey).(string)
+	return token
+}
+
+// ContextSetPermissions records permissions that are already known for the request, so
+// that they do not have to be loaded from the database again.
+func ContextSetPermissions(r *http.Request, permissions *domain.Permissions) *http.Request {
+	ctx := context.WithValue(r.Context(), permissionsContextKey, permissions)
+	return r.WithContext(ctx)
+}
+
+func ContextGetPermissions(r *http.Request) (*domain.Permissions, bool) {
+	permissions, ok := r.Context().Value(permissionsContextKey).(*domain.Permissions)
+	return permissions, ok
+}
+
+// contextPermissions returns the permissions RequirePermission loaded for the request, or
+// none if the route was not guarded by it.
+func contextPermissions(r *http.Request) domain.Permissions {
+	permissions, ok := ContextGetPermissions(r)
+	if !ok {
+		return nil
+	}
+	return *permissions
+}
+
+// ContextSetApiKey records the API key the request was authenticated with, if any.
+func ContextSetApiKey(r *http.Request, key *domain.ApiKey) *http.Request {
+	ctx := context.WithValue(r.Context(), apiKeyContextKey, key)
+	return r.WithContext(ctx)
+}
+
+func ContextGetApiKey(r *http.Request) (*domain.ApiKey, bool) {
+	key, ok := r.Context().Value(apiKeyContextKey).(*domain.ApiKey)
+	return key, ok
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"net/http"
+)
+
+type HistoryHandler struct {
+	historyService service.HistoryService
+}
+
+func (h *HistoryHandler) RecordWatchHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.HistoryEntry
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	entry, err := h.historyService.RecordWatch(r.Context(), ContextGetUser(r), contextPermissions(r), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, service.ErrNotPermitted):
+			helper.NotPermittedResponse(w, r)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"entry": entry}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *HistoryHandler) GetHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	filters := domain.Filters{
+		Page:         readInt(qs, "page", 1, v),
+		PageSize:     readInt(qs, "page_size", 20, v),
+		Sort:         readString(qs, "sort", "-watched_at"),
+		SortSafeList: []string{"id", "watched_at", "-id", "-watched_at"},
+	}
+
+	if domain.ValidateFilters(v, filters); !v.Valid() {
+		helper.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	entries, metadata, err := h.historyService.GetHistory(r.Context(), ContextGetUser(r), filters)
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"history": entries, "metadata": metadata}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *HistoryHandler) DeleteEntryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = h.historyService.DeleteEntry(r.Context(), ContextGetUser(r), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "history entry successfully deleted"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *HistoryHandler) GetStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.historyService.GetStats(r.Context(), ContextGetUser(r))
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"stats": stats}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewHistoryHandler(historyService service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{
+		historyService: historyService,
+	}
+}
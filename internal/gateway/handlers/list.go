@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"net/http"
+)
+
+var listSortSafeList = []string{"id", "name", "created_at", "updated_at", "-id", "-name", "-created_at", "-updated_at"}
+
+type ListHandler struct {
+	listService service.ListService
+}
+
+func (l *ListHandler) CreateListHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.List
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	list, err := l.listService.CreateList(r.Context(), ContextGetUser(r), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		if errors.As(err, &valErr) {
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+			return
+		}
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/me/lists/%d", list.ID))
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"list": list}, headers); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) GetMyListsHandler(w http.ResponseWriter, r *http.Request) {
+	filters, ok := readListFilters(w, r)
+	if !ok {
+		return
+	}
+
+	lists, metadata, err := l.listService.GetMyLists(r.Context(), ContextGetUser(r), filters)
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"lists": lists, "metadata": metadata}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) GetPublicListsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	filters, ok := readListFilters(w, r)
+	if !ok {
+		return
+	}
+
+	lists, metadata, err := l.listService.GetPublicLists(r.Context(), id, filters)
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"lists": lists, "metadata": metadata}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) ShowListHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	list, err := l.listService.GetList(r.Context(), ContextGetUser(r), id)
+	l.writeList(w, r, list, err)
+}
+
+func (l *ListHandler) ShowSharedListHandler(w http.ResponseWriter, r *http.Request) {
+	slug := helper.ReadStringParam(r, "slug")
+
+	list, err := l.listService.GetListBySlug(r.Context(), ContextGetUser(r), slug)
+	l.writeList(w, r, list, err)
+}
+
+func (l *ListHandler) UpdateListHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var input dto.UpdateList
+	if err := helper.ReadJSON(w, r, &input); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	list, err := l.listService.UpdateList(r.Context(), ContextGetUser(r), id, &input)
+	l.writeList(w, r, list, err)
+}
+
+func (l *ListHandler) DeleteListHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = l.listService.DeleteList(r.Context(), ContextGetUser(r), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "list successfully deleted"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (l *ListHandler) AddMovieHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var input dto.ListItem
+	if err := helper.ReadJSON(w, r, &input); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	list, err := l.listService.AddMovie(r.Context(), ContextGetUser(r), id, &input)
+	l.writeList(w, r, list, err)
+}
+
+func (l *ListHandler) ReorderMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var input dto.ReorderList
+	if err := helper.ReadJSON(w, r, &input); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	list, err := l.listService.ReorderMovies(r.Context(), ContextGetUser(r), id, &input)
+	l.writeList(w, r, list, err)
+}
+
+func (l *ListHandler) RemoveMovieHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	movieID, err := helper.ReadNamedParam(r, "movie_id")
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	list, err := l.listService.RemoveMovie(r.Context(), ContextGetUser(r), id, movieID)
+	l.writeList(w, r, list, err)
+}
+
+func (l *ListHandler) writeList(w http.ResponseWriter, r *http.Request, list *domain.List, err error) {
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrDuplicateItem):
+			helper.ErrorResponse(w, r, http.StatusConflict, "the movie is already in this list")
+		case errors.Is(err, repository.ErrEditConflict):
+			helper.EditConflictResponse(w, r)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"list": list}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func readListFilters(w http.ResponseWriter, r *http.Request) (domain.Filters, bool) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	filters := domain.Filters{
+		Page:         readInt(qs, "page", 1, v),
+		PageSize:     readInt(qs, "page_size", 20, v),
+		Sort:         readString(qs, "sort", "-updated_at"),
+		SortSafeList: listSortSafeList,
+	}
+
+	if domain.ValidateFilters(v, filters); !v.Valid() {
+		helper.FailedValidationResponse(w, r, v.Errors)
+		return filters, false
+	}
+
+	return filters, true
+}
+
+func NewListHandler(listService service.ListService) *ListHandler {
+	return &ListHandler{
+		listService: listService,
+	}
+}
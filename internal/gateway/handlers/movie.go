@@ -3,14 +3,18 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
 	"net/http"
 )
 
+var movieSortSafeList = []string{"id", "title", "year", "runtime", "-id", "-title", "-year", "-runtime"}
+
 type MovieHandler struct {
 	movieService service.MovieService
 }
@@ -23,7 +27,7 @@ func (m *MovieHandler) CreateMovieHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	movie, err := m.movieService.CreateMovie(r.Context(), &payload)
+	movie, err := m.movieService.CreateMovie(r.Context(), ContextGetUser(r), &payload)
 	if err != nil {
 		var valErr validator.ValidationError
 		if errors.As(err, &valErr) {
@@ -66,13 +70,130 @@ func (m *MovieHandler) ShowMovieHandler(w http.ResponseWriter, r *http.Request)
 }
 
 func (m *MovieHandler) GetMoviesHandler(w http.ResponseWriter, r *http.Request) {
-	movies, err := m.movieService.GetMovies(r.Context())
+	qs := r.URL.Query()
+
+	if qs.Has("q") {
+		m.searchMovies(w, r)
+		return
+	}
+
+	if qs.Has("cursor") {
+		m.getMoviesByCursor(w, r)
+		return
+	}
+
+	v := validator.New()
+
+	title := readString(qs, "title", "")
+	genres := readCSV(qs, "genres", []string{})
+
+	filters := domain.Filters{
+		Page:         readInt(qs, "page", 1, v),
+		PageSize:     readInt(qs, "page_size", 20, v),
+		Sort:         readString(qs, "sort", "id"),
+		SortSafeList: movieSortSafeList,
+	}
+
+	if domain.ValidateFilters(v, filters); !v.Valid() {
+		helper.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movies, metadata, err := m.movieService.GetMovies(r.Context(), title, genres, filters)
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movie": movies, "metadata": metadata}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+// getMoviesByCursor serves GET /v1/movies?cursor=... using keyset pagination. An empty
+// cursor starts from the first page; the sort order is carried inside subsequent cursors.
+func (m *MovieHandler) getMoviesByCursor(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	title := readString(qs, "title", "")
+	genres := readCSV(qs, "genres", []string{})
+	sort := readString(qs, "sort", "id")
+
+	var cursor *domain.Cursor
+	if value := qs.Get("cursor"); value != "" {
+		var err error
+		cursor, err = utils.DecodeCursor(value)
+		switch {
+		case errors.Is(err, utils.ErrCursorSecret):
+			helper.ServerErrorResponse(w, r, err)
+			return
+		case err != nil:
+			v.AddError("cursor", "must be a valid cursor")
+		case qs.Has("sort") && sort != cursor.Sort:
+			v.AddError("sort", "must match the sort of the cursor")
+		default:
+			sort = cursor.Sort
+		}
+	}
+
+	filters := domain.Filters{
+		Page:         1,
+		PageSize:     readInt(qs, "page_size", 20, v),
+		Sort:         sort,
+		SortSafeList: movieSortSafeList,
+	}
+
+	if domain.ValidateFilters(v, filters); !v.Valid() {
+		helper.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movies, metadata, err := m.movieService.GetMoviesByCursor(r.Context(), title, genres, filters, cursor)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidCursor):
+			v.AddError("cursor", "must be a valid cursor")
+			helper.FailedValidationResponse(w, r, v.Errors)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movie": movies, "metadata": metadata}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+// searchMovies serves GET /v1/movies?q=... as a full-text search ordered by relevance.
+func (m *MovieHandler) searchMovies(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	query := readString(qs, "q", "")
+	mode := readString(qs, "search_mode", domain.SearchModeWebsearch)
+
+	filters := domain.Filters{
+		Page:         readInt(qs, "page", 1, v),
+		PageSize:     readInt(qs, "page_size", 20, v),
+		Sort:         "rank",
+		SortSafeList: []string{"rank"},
+	}
+
+	domain.ValidateMovieSearch(v, query, mode)
+	if domain.ValidateFilters(v, filters); !v.Valid() {
+		helper.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	results, metadata, err := m.movieService.SearchMovies(r.Context(), query, mode, filters)
 	if err != nil {
 		helper.ServerErrorResponse(w, r, err)
 		return
 	}
 
-	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movie": movies}, nil); err != nil {
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"movie": results, "metadata": metadata}, nil); err != nil {
 		helper.ServerErrorResponse(w, r, err)
 	}
 }
@@ -90,7 +211,7 @@ func (m *MovieHandler) UpdateMovieHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	updatedMovie, err := m.movieService.UpdateMovie(r.Context(), id, &input)
+	updatedMovie, err := m.movieService.UpdateMovie(r.Context(), ContextGetUser(r), contextPermissions(r), id, &input)
 	if err != nil {
 		var valErr validator.ValidationError
 		if errors.As(err, &valErr) {
@@ -103,6 +224,8 @@ func (m *MovieHandler) UpdateMovieHandler(w http.ResponseWriter, r *http.Request
 			helper.EditConflictResponse(w, r)
 		case errors.Is(err, repository.ErrRecordNotFound):
 			helper.NotFoundResponse(w, r)
+		case errors.Is(err, service.ErrNotPermitted):
+			helper.NotPermittedResponse(w, r)
 		default:
 			helper.ServerErrorResponse(w, r, err)
 		}
@@ -121,12 +244,14 @@ func (m *MovieHandler) DeleteMovieHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = m.movieService.DeleteMovie(r.Context(), id)
+	err = m.movieService.DeleteMovie(r.Context(), ContextGetUser(r), contextPermissions(r), id)
 
 	if err != nil {
 		switch {
 		case errors.Is(err, repository.ErrRecordNotFound):
 			helper.NotFoundResponse(w, r)
+		case errors.Is(err, service.ErrNotPermitted):
+			helper.NotPermittedResponse(w, r)
 		default:
 			helper.ServerErrorResponse(w, r, err)
 		}
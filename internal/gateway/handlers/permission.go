@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"net/http"
+)
+
+type PermissionHandler struct {
+	permissionService service.PermissionService
+}
+
+func (p *PermissionHandler) GetPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	permissions, err := p.permissionService.GetPermissions(r.Context())
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"permissions": permissions}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) CreatePermissionHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.Permission
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	permission, err := p.permissionService.CreatePermission(r.Context(), ContextGetUser(r), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrDuplicateCode):
+			helper.ErrorResponse(w, r, http.StatusConflict, "a permission with this code already exists")
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"permission": permission}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) GetAuditHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	userID := readInt(qs, "user_id", 0, v)
+
+	filters := domain.Filters{
+		Page:         readInt(qs, "page", 1, v),
+		PageSize:     readInt(qs, "page_size", 20, v),
+		Sort:         readString(qs, "sort", "-created_at"),
+		SortSafeList: []string{"id", "created_at", "-id", "-created_at"},
+	}
+
+	if domain.ValidateFilters(v, filters); !v.Valid() {
+		helper.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	entries, metadata, err := p.permissionService.GetAudit(r.Context(), int64(userID), filters)
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"audit": entries, "metadata": metadata}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) GetUserPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	permissions, err := p.permissionService.GetUserPermissions(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"permissions": permissions}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) GrantPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload dto.PermissionCodes
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	permissions, err := p.permissionService.GrantPermissions(r.Context(), ContextGetUser(r), id, &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"permissions": permissions}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) RevokePermissionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	code := helper.ReadStringParam(r, "code")
+
+	if err = p.permissionService.RevokePermission(r.Context(), ContextGetUser(r), id, code); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "permission successfully revoked"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) GetRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := p.permissionService.GetRoles(r.Context())
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"roles": roles}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) CreateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.Role
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	role, err := p.permissionService.CreateRole(r.Context(), ContextGetUser(r), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrDuplicateRole):
+			helper.ErrorResponse(w, r, http.StatusConflict, "a role with this name already exists")
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"role": role}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) GrantRolePermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.PermissionCodes
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	role, err := p.permissionService.GrantRolePermissions(r.Context(), ContextGetUser(r), helper.ReadStringParam(r, "name"), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"role": role}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) RevokeRolePermissionHandler(w http.ResponseWriter, r *http.Request) {
+	name := helper.ReadStringParam(r, "name")
+	code := helper.ReadStringParam(r, "code")
+
+	if err := p.permissionService.RevokeRolePermission(r.Context(), ContextGetUser(r), name, code); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "permission successfully revoked"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) GetUserRolesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	roles, err := p.permissionService.GetUserRoles(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"roles": roles}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) AssignRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload dto.RoleAssignment
+	if err = helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	roles, err := p.permissionService.AssignRole(r.Context(), ContextGetUser(r), id, &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"roles": roles}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PermissionHandler) UnassignRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = p.permissionService.UnassignRole(r.Context(), ContextGetUser(r), id, helper.ReadStringParam(r, "name")); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "role successfully unassigned"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewPermissionHandler(permissionService service.PermissionService) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+	}
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"net/http"
+)
+
+type PersonHandler struct {
+	personService service.PersonService
+}
+
+func (p *PersonHandler) CreatePersonHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.Person
+
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	person, err := p.personService.CreatePerson(r.Context(), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		if errors.As(err, &valErr) {
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+			return
+		}
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/people/%d", person.ID))
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"person": person}, headers); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) ShowPersonHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	person, err := p.personService.GetPersonById(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"person": person}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) GetPeopleHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	name := readString(qs, "name", "")
+
+	filters := domain.Filters{
+		Page:         readInt(qs, "page", 1, v),
+		PageSize:     readInt(qs, "page_size", 20, v),
+		Sort:         readString(qs, "sort", "id"),
+		SortSafeList: []string{"id", "name", "-id", "-name"},
+	}
+
+	if domain.ValidateFilters(v, filters); !v.Valid() {
+		helper.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	people, metadata, err := p.personService.GetPeople(r.Context(), name, filters)
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"people": people, "metadata": metadata}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) UpdatePersonHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var input dto.UpdatePerson
+	if err := helper.ReadJSON(w, r, &input); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	person, err := p.personService.UpdatePerson(r.Context(), id, &input)
+	if err != nil {
+		var valErr validator.ValidationError
+		if errors.As(err, &valErr) {
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+			return
+		}
+
+		switch {
+		case errors.Is(err, repository.ErrEditConflict):
+			helper.EditConflictResponse(w, r)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"person": person}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) DeletePersonHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = p.personService.DeletePerson(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "person successfully deleted"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) GetFilmographyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	credits, err := p.personService.GetFilmography(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"filmography": credits}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) GetMovieCreditsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	credits, err := p.personService.GetMovieCredits(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"credits": credits}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) CreateCreditHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload dto.Credit
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	credit, err := p.personService.AddCredit(r.Context(), id, &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"credit": credit}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (p *PersonHandler) DeleteCreditHandler(w http.ResponseWriter, r *http.Request) {
+	movieID, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	creditID, err := helper.ReadNamedParam(r, "credit_id")
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = p.personService.RemoveCredit(r.Context(), movieID, creditID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "credit successfully deleted"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewPersonHandler(personService service.PersonService) *PersonHandler {
+	return &PersonHandler{
+		personService: personService,
+	}
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"net/http"
+)
+
+var reviewSortSafeList = []string{"id", "created_at", "updated_at", "-id", "-created_at", "-updated_at"}
+
+type ReviewHandler struct {
+	reviewService service.ReviewService
+}
+
+func (rh *ReviewHandler) GetReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	filters := domain.Filters{
+		Page:         readInt(qs, "page", 1, v),
+		PageSize:     readInt(qs, "page_size", 20, v),
+		Sort:         readString(qs, "sort", "-created_at"),
+		SortSafeList: reviewSortSafeList,
+	}
+
+	if domain.ValidateFilters(v, filters); !v.Valid() {
+		helper.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	reviews, metadata, err := rh.reviewService.GetPublishedReviews(r.Context(), id, filters)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"reviews": reviews, "metadata": metadata}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (rh *ReviewHandler) GetModerationQueueHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	status := readString(qs, "status", domain.ReviewStatusPending)
+
+	filters := domain.Filters{
+		Page:         readInt(qs, "page", 1, v),
+		PageSize:     readInt(qs, "page_size", 20, v),
+		Sort:         readString(qs, "sort", "created_at"),
+		SortSafeList: reviewSortSafeList,
+	}
+
+	v.Check(validator.PermittedValue(status, domain.ReviewStatusPending, domain.ReviewStatusPublished, domain.ReviewStatusRejected), "status", "invalid status")
+	if domain.ValidateFilters(v, filters); !v.Valid() {
+		helper.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	reviews, metadata, err := rh.reviewService.GetReviewsByStatus(r.Context(), status, filters)
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"reviews": reviews, "metadata": metadata}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (rh *ReviewHandler) CreateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var payload dto.Review
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	review, err := rh.reviewService.CreateReview(r.Context(), ContextGetUser(r), id, &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrDuplicateReview):
+			helper.ErrorResponse(w, r, http.StatusConflict, "you have already reviewed this movie")
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"review": review}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (rh *ReviewHandler) UpdateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	movieID, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	reviewID, err := helper.ReadNamedParam(r, "review_id")
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var input dto.UpdateReview
+	if err := helper.ReadJSON(w, r, &input); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	review, err := rh.reviewService.UpdateReview(r.Context(), ContextGetUser(r), movieID, reviewID, &input)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, service.ErrNotPermitted):
+			helper.NotPermittedResponse(w, r)
+		case errors.Is(err, repository.ErrEditConflict):
+			helper.EditConflictResponse(w, r)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"review": review}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (rh *ReviewHandler) DeleteReviewHandler(w http.ResponseWriter, r *http.Request) {
+	movieID, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	reviewID, err := helper.ReadNamedParam(r, "review_id")
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = rh.reviewService.DeleteReview(r.Context(), ContextGetUser(r), movieID, reviewID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotPermitted):
+			helper.NotPermittedResponse(w, r)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "review successfully deleted"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (rh *ReviewHandler) ApproveReviewHandler(w http.ResponseWriter, r *http.Request) {
+	rh.moderateReview(w, r, domain.ReviewStatusPublished)
+}
+
+func (rh *ReviewHandler) RejectReviewHandler(w http.ResponseWriter, r *http.Request) {
+	rh.moderateReview(w, r, domain.ReviewStatusRejected)
+}
+
+func (rh *ReviewHandler) moderateReview(w http.ResponseWriter, r *http.Request, status string) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	var input dto.ModerateReview
+	if err := helper.ReadJSON(w, r, &input); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	review, err := rh.reviewService.ModerateReview(r.Context(), ContextGetUser(r), id, status, &input)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrEditConflict):
+			helper.EditConflictResponse(w, r)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"review": review}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewReviewHandler(reviewService service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+	}
+}
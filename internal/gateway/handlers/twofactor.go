@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"net/http"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+func (t *TwoFactorHandler) GetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := t.twoFactorService.GetStatus(r.Context(), ContextGetUser(r))
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"two_factor": status}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (t *TwoFactorHandler) EnrollHandler(w http.ResponseWriter, r *http.Request) {
+	enrollment, err := t.twoFactorService.Enroll(r.Context(), ContextGetUser(r))
+	if err != nil {
+		t.errorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"enrollment": enrollment}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (t *TwoFactorHandler) ConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.TwoFactorCode
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	codes, err := t.twoFactorService.Confirm(r.Context(), ContextGetUser(r), &payload)
+	if err != nil {
+		t.errorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"recovery_codes": codes}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (t *TwoFactorHandler) RegenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.TwoFactorCode
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	codes, err := t.twoFactorService.RegenerateRecoveryCodes(r.Context(), ContextGetUser(r), &payload)
+	if err != nil {
+		t.errorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"recovery_codes": codes}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (t *TwoFactorHandler) DisableHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.DisableTwoFactor
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := t.twoFactorService.Disable(r.Context(), ContextGetUser(r), &payload); err != nil {
+		t.errorResponse(w, r, err)
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "two-factor authentication has been disabled"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (t *TwoFactorHandler) errorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var valErr validator.ValidationError
+	switch {
+	case errors.As(err, &valErr):
+		helper.FailedValidationResponse(w, r, valErr.Errors)
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		helper.FailedValidationResponse(w, r, map[string]string{"code": "invalid or already used code"})
+	case errors.Is(err, service.ErrInvalidCredentials):
+		helper.InvalidCredentialsResponse(w, r)
+	case errors.Is(err, service.ErrTwoFactorEnabled), errors.Is(err, service.ErrTwoFactorNotEnabled):
+		helper.ErrorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
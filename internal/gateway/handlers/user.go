@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"github.com/tomasen/realip"
 	"net/http"
 )
 
@@ -78,13 +80,19 @@ func (u *UserHandler) CreateAuthenticationTokenHandler(w http.ResponseWriter, r
 		return
 	}
 
-	token, err := u.userService.CreateAuthenticationToken(r.Context(), payload)
+	payload.IP = realip.FromRequest(r)
+	payload.UserAgent = r.UserAgent()
+
+	tokens, err := u.userService.CreateAuthenticationToken(r.Context(), payload)
 	if err != nil {
 		var valErr validator.ValidationError
+		var blockedErr *service.LoginBlockedError
 		switch {
 		case errors.As(err, &valErr):
 			helper.FailedValidationResponse(w, r, valErr.Errors)
-		case err.Error() == "invalid credentials":
+		case errors.As(err, &blockedErr):
+			loginBlockedResponse(w, r, blockedErr)
+		case errors.Is(err, service.ErrInvalidCredentials):
 			helper.InvalidCredentialsResponse(w, r)
 		default:
 			helper.ServerErrorResponse(w, r, err)
@@ -92,7 +100,490 @@ func (u *UserHandler) CreateAuthenticationTokenHandler(w http.ResponseWriter, r
 		return
 	}
 
-	if err := helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"authentication_token": token}, nil); err != nil {
+	writeAuthenticationTokens(w, r, tokens)
+}
+
+func (u *UserHandler) CreateMagicLinkTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.EmailTokenRequest
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := u.userService.CreateMagicLinkToken(r.Context(), &payload); err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := helper.Envelope{"message": "if an activated account exists for this email address, you will receive a sign-in link"}
+
+	if err := helper.WriteJSON(w, http.StatusAccepted, env, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) CreateMagicLinkAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.MagicLinkLogin
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	payload.IP = realip.FromRequest(r)
+	payload.UserAgent = r.UserAgent()
+
+	tokens, err := u.userService.CreateMagicLinkAuthenticationToken(r.Context(), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		var blockedErr *service.LoginBlockedError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.As(err, &blockedErr):
+			loginBlockedResponse(w, r, blockedErr)
+		case errors.Is(err, service.ErrInvalidToken):
+			helper.InvalidAuthenticationTokenResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	writeAuthenticationTokens(w, r, tokens)
+}
+
+func (u *UserHandler) CreateTwoFactorAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.TwoFactorLogin
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	payload.IP = realip.FromRequest(r)
+	payload.UserAgent = r.UserAgent()
+
+	tokens, err := u.userService.CreateTwoFactorAuthenticationToken(r.Context(), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		var blockedErr *service.LoginBlockedError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, service.ErrInvalidToken):
+			helper.InvalidAuthenticationTokenResponse(w, r)
+		case errors.As(err, &blockedErr):
+			loginBlockedResponse(w, r, blockedErr)
+		case errors.Is(err, service.ErrInvalidTwoFactorCode):
+			helper.ErrorResponse(w, r, http.StatusUnauthorized, "invalid or already used two-factor code")
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	writeAuthenticationTokens(w, r, tokens)
+}
+
+func (u *UserHandler) RefreshAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.RefreshTokenRequest
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	payload.IP = realip.FromRequest(r)
+	payload.UserAgent = r.UserAgent()
+
+	tokens, err := u.userService.RefreshAuthenticationToken(r.Context(), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, service.ErrInvalidToken):
+			helper.InvalidAuthenticationTokenResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"authentication_token": tokens.Authentication, "refresh_token": tokens.Refresh}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) RevokeAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	if err := u.userService.RevokeAuthenticationToken(r.Context(), ContextGetToken(r)); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.InvalidAuthenticationTokenResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "you have been signed out"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) RevokeAllAuthenticationTokensHandler(w http.ResponseWriter, r *http.Request) {
+	user := ContextGetUser(r)
+
+	if err := u.userService.RevokeAllAuthenticationTokens(r.Context(), user.ID); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "you have been signed out of all sessions"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) GetSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	sessions, err := u.userService.GetSessions(r.Context(), ContextGetUser(r), ContextGetToken(r))
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"sessions": sessions}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = u.userService.RevokeSession(r.Context(), ContextGetUser(r), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "session successfully revoked"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) UnlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = u.userService.UnlockUser(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "the user account has been unlocked"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) RevokeUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := helper.ReadParams(r)
+	if err != nil {
+		helper.NotFoundResponse(w, r)
+		return
+	}
+
+	if err = u.userService.RevokeUserSessions(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "all sessions of the user have been revoked"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) CreateActivationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.EmailTokenRequest
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := u.userService.CreateActivationToken(r.Context(), payload); err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := helper.Envelope{"message": "if an unactivated account exists for this email address, you will receive activation instructions"}
+
+	if err := helper.WriteJSON(w, http.StatusAccepted, env, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) CreatePasswordResetTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.EmailTokenRequest
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := u.userService.CreatePasswordResetToken(r.Context(), payload); err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := helper.Envelope{"message": "if an activated account exists for this email address, you will receive password reset instructions"}
+
+	if err := helper.WriteJSON(w, http.StatusAccepted, env, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload *dto.ResetPasswordRequest
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := u.userService.ResetPassword(r.Context(), payload); err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.ErrorResponse(w, r, http.StatusUnprocessableEntity, "invalid or expired password reset token")
+		case errors.Is(err, repository.ErrEditConflict):
+			helper.EditConflictResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "your password was successfully reset"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) GetProfileHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := u.userService.GetProfile(r.Context(), ContextGetUser(r))
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"user": user}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) UpdateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.UpdateProfile
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user, err := u.userService.UpdateProfile(r.Context(), ContextGetUser(r), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrEditConflict):
+			helper.EditConflictResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"user": user}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.ChangePassword
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := u.userService.ChangePassword(r.Context(), ContextGetUser(r), ContextGetToken(r), &payload); err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, service.ErrInvalidCredentials):
+			helper.InvalidCredentialsResponse(w, r)
+		case errors.Is(err, repository.ErrEditConflict):
+			helper.EditConflictResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "your password was successfully changed"}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) RequestEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.ChangeEmail
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := u.userService.RequestEmailChange(r.Context(), ContextGetUser(r), &payload); err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, service.ErrInvalidCredentials):
+			helper.InvalidCredentialsResponse(w, r)
+		case errors.Is(err, repository.ErrDuplicateEmail):
+			helper.ErrorResponse(w, r, http.StatusConflict, "a user with this email address already exists")
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := helper.Envelope{"message": "a confirmation token has been sent to the new email address"}
+
+	if err := helper.WriteJSON(w, http.StatusAccepted, env, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) ConfirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.ConfirmEmailChange
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	user, err := u.userService.ConfirmEmailChange(r.Context(), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.ErrorResponse(w, r, http.StatusUnprocessableEntity, "invalid or expired email change token")
+		case errors.Is(err, repository.ErrDuplicateEmail):
+			helper.ErrorResponse(w, r, http.StatusConflict, "a user with this email address already exists")
+		case errors.Is(err, repository.ErrEditConflict):
+			helper.EditConflictResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"user": user}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) ExportAccountHandler(w http.ResponseWriter, r *http.Request) {
+	export, err := u.userService.ExportAccount(r.Context(), ContextGetUser(r))
+	if err != nil {
+		helper.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Content-Disposition", `attachment; filename="cinemaniac-export.json"`)
+
+	if err = helper.WriteJSON(w, http.StatusOK, helper.Envelope{"export": export}, headers); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) DeleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	var payload dto.DeleteAccount
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, r, err)
+		return
+	}
+
+	deletion, err := u.userService.DeleteAccount(r.Context(), ContextGetUser(r), ContextGetToken(r), &payload)
+	if err != nil {
+		var valErr validator.ValidationError
+		switch {
+		case errors.As(err, &valErr):
+			helper.FailedValidationResponse(w, r, valErr.Errors)
+		case errors.Is(err, service.ErrInvalidCredentials):
+			helper.InvalidCredentialsResponse(w, r)
+		case errors.Is(err, service.ErrDeletionScheduled):
+			helper.ErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	env := helper.Envelope{"deletion": deletion, "message": "your account is scheduled for deletion"}
+
+	if err = helper.WriteJSON(w, http.StatusAccepted, env, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (u *UserHandler) CancelAccountDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	if err := u.userService.CancelAccountDeletion(r.Context(), ContextGetUser(r)); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, r)
+		default:
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusOK, helper.Envelope{"message": "account deletion cancelled"}, nil); err != nil {
 		helper.ServerErrorResponse(w, r, err)
 	}
 }
@@ -102,3 +593,28 @@ func NewUserHandler(userService service.UserService) *UserHandler {
 		userService: userService,
 	}
 }
+
+func loginBlockedResponse(w http.ResponseWriter, r *http.Request, err *service.LoginBlockedError) {
+	if err.Locked {
+		helper.AccountLockedResponse(w, r, err.RetryAfter)
+		return
+	}
+
+	helper.LoginThrottledResponse(w, r, err.RetryAfter)
+}
+
+// writeAuthenticationTokens responds to a successful first sign-in step with either the
+// token pair or, for accounts with 2FA, the token to complete sign-in with.
+func writeAuthenticationTokens(w http.ResponseWriter, r *http.Request, tokens *domain.AuthenticationTokens) {
+	if tokens.TwoFactorPending != nil {
+		env := helper.Envelope{"two_factor_token": tokens.TwoFactorPending, "message": "a two-factor code is required to complete sign-in"}
+		if err := helper.WriteJSON(w, http.StatusOK, env, nil); err != nil {
+			helper.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := helper.WriteJSON(w, http.StatusCreated, helper.Envelope{"authentication_token": tokens.Authentication, "refresh_token": tokens.Refresh}, nil); err != nil {
+		helper.ServerErrorResponse(w, r, err)
+	}
+}
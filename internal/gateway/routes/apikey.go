@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"net/http"
+)
+
+func apiKeyRoutes(route *httprouter.Router, handler *handlers.ApiKeyHandler) {
+	route.HandlerFunc(http.MethodGet, "/v1/me/api-keys", middleware.RequireInteractiveUser(handler.GetApiKeysHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/me/api-keys", middleware.RequireInteractiveUser(handler.CreateApiKeyHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/me/api-keys/:id", middleware.RequireInteractiveUser(handler.ShowApiKeyHandler))
+	route.HandlerFunc(http.MethodPatch, "/v1/me/api-keys/:id", middleware.RequireInteractiveUser(handler.UpdateApiKeyHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/me/api-keys/:id", middleware.RequireInteractiveUser(handler.DeleteApiKeyHandler))
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"net/http"
+)
+
+func historyRoutes(route *httprouter.Router, handler *handlers.HistoryHandler, permission repository.PermissionRepository) {
+	route.HandlerFunc(http.MethodGet, "/v1/me/history", middleware.RequirePermission(permission, "history:read", handler.GetHistoryHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/me/history", middleware.RequirePermission(permission, "history:write", handler.RecordWatchHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/me/history/stats", middleware.RequirePermission(permission, "history:read", handler.GetStatsHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/me/history/:id", middleware.RequirePermission(permission, "history:write", handler.DeleteEntryHandler))
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"net/http"
+)
+
+func listRoutes(route *httprouter.Router, handler *handlers.ListHandler, permission repository.PermissionRepository) {
+	route.HandlerFunc(http.MethodGet, "/v1/me/lists", middleware.RequirePermission(permission, "lists:read", handler.GetMyListsHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/me/lists", middleware.RequirePermission(permission, "lists:write", handler.CreateListHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/me/lists/:id", middleware.RequirePermission(permission, "lists:read", handler.ShowListHandler))
+	route.HandlerFunc(http.MethodPatch, "/v1/me/lists/:id", middleware.RequirePermission(permission, "lists:write", handler.UpdateListHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/me/lists/:id", middleware.RequirePermission(permission, "lists:write", handler.DeleteListHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/me/lists/:id/movies", middleware.RequirePermission(permission, "lists:write", handler.AddMovieHandler))
+	route.HandlerFunc(http.MethodPut, "/v1/me/lists/:id/movies", middleware.RequirePermission(permission, "lists:write", handler.ReorderMoviesHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/me/lists/:id/movies/:movie_id", middleware.RequirePermission(permission, "lists:write", handler.RemoveMovieHandler))
+
+	route.HandlerFunc(http.MethodGet, "/v1/lists/:slug", handler.ShowSharedListHandler)
+	route.HandlerFunc(http.MethodGet, "/v1/users/:id/lists", handler.GetPublicListsHandler)
+}
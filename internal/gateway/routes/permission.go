@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"net/http"
+)
+
+func permissionRoutes(route *httprouter.Router, handler *handlers.PermissionHandler, permission repository.PermissionRepository) {
+	route.HandlerFunc(http.MethodGet, "/v1/permissions", middleware.RequirePermission(permission, "permissions:admin", handler.GetPermissionsHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/permissions", middleware.RequirePermission(permission, "permissions:admin", handler.CreatePermissionHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/permissions/audit", middleware.RequirePermission(permission, "permissions:admin", handler.GetAuditHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/users/:id/permissions", middleware.RequirePermission(permission, "permissions:admin", handler.GetUserPermissionsHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/users/:id/permissions", middleware.RequirePermission(permission, "permissions:admin", handler.GrantPermissionsHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/users/:id/permissions/:code", middleware.RequirePermission(permission, "permissions:admin", handler.RevokePermissionHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/roles", middleware.RequirePermission(permission, "permissions:admin", handler.GetRolesHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/roles", middleware.RequirePermission(permission, "permissions:admin", handler.CreateRoleHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/roles/:name/permissions", middleware.RequirePermission(permission, "permissions:admin", handler.GrantRolePermissionsHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/roles/:name/permissions/:code", middleware.RequirePermission(permission, "permissions:admin", handler.RevokeRolePermissionHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/users/:id/roles", middleware.RequirePermission(permission, "permissions:admin", handler.GetUserRolesHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/users/:id/roles", middleware.RequirePermission(permission, "permissions:admin", handler.AssignRoleHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/users/:id/roles/:name", middleware.RequirePermission(permission, "permissions:admin", handler.UnassignRoleHandler))
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"net/http"
+)
+
+func personRoutes(route *httprouter.Router, handler *handlers.PersonHandler, permission repository.PermissionRepository) {
+	route.HandlerFunc(http.MethodPost, "/v1/people", middleware.RequirePermission(permission, "movies:write", handler.CreatePersonHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/people", middleware.RequirePermission(permission, "movies:read", handler.GetPeopleHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/people/:id", middleware.RequirePermission(permission, "movies:read", handler.ShowPersonHandler))
+	route.HandlerFunc(http.MethodPatch, "/v1/people/:id", middleware.RequirePermission(permission, "movies:write", handler.UpdatePersonHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/people/:id", middleware.RequirePermission(permission, "movies:write", handler.DeletePersonHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/people/:id/filmography", middleware.RequirePermission(permission, "movies:read", handler.GetFilmographyHandler))
+
+	route.HandlerFunc(http.MethodGet, "/v1/movies/:id/credits", middleware.RequirePermission(permission, "movies:read", handler.GetMovieCreditsHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/movies/:id/credits", middleware.RequirePermission(permission, "movies:write", handler.CreateCreditHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/movies/:id/credits/:credit_id", middleware.RequirePermission(permission, "movies:write", handler.DeleteCreditHandler))
+}
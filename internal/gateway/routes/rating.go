@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"net/http"
+)
+
+func ratingRoutes(route *httprouter.Router, handler *handlers.RatingHandler, permission repository.PermissionRepository) {
+	route.HandlerFunc(http.MethodGet, "/v1/movies/:id/rating", middleware.RequirePermission(permission, "ratings:read", handler.ShowRatingHandler))
+	route.HandlerFunc(http.MethodPut, "/v1/movies/:id/rating", middleware.RequirePermission(permission, "ratings:write", handler.PutRatingHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/movies/:id/rating", middleware.RequirePermission(permission, "ratings:write", handler.DeleteRatingHandler))
+}
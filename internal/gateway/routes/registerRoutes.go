@@ -10,11 +10,14 @@ import (
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/pkg/jwt"
 	"github.com/saleh-ghazimoradi/Cinemaniac/pkg/notification"
 	"net/http"
 )
 
-func RegisterRoutes(db *sql.DB) http.Handler {
+// RegisterRoutes builds the API. permissionRepository is passed in rather than created here
+// so that the server can share its cache with the listener that invalidates it.
+func RegisterRoutes(db *sql.DB, signer *jwt.Signer, permissionRepository repository.PermissionRepository) http.Handler {
 	router := httprouter.New()
 
 	router.NotFound = http.HandlerFunc(helper.NotFoundResponse)
@@ -23,20 +26,52 @@ func RegisterRoutes(db *sql.DB) http.Handler {
 	movieRepository := repository.NewMovieRepository(db, db)
 	userRepository := repository.NewUserRepository(db, db)
 	tokenRepository := repository.NewTokenRepository(db, db)
-	permissionRepository := repository.NewPermissionRepository(db, db)
+	personRepository := repository.NewPersonRepository(db, db)
+	ratingRepository := repository.NewRatingRepository(db, db)
+	reviewRepository := repository.NewReviewRepository(db, db)
+	listRepository := repository.NewListRepository(db, db)
+	historyRepository := repository.NewHistoryRepository(db, db)
+	apiKeyRepository := repository.NewApiKeyRepository(db, db)
+	twoFactorRepository := repository.NewTwoFactorRepository(db, db)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(db, db)
+	accountRepository := repository.NewAccountRepository(db, db)
 
 	txService := transaction.NewTXService(db)
 	SMTP, _ := notification.NewMailer(config.AppConfig.SMTP.Host, config.AppConfig.SMTP.Port, config.AppConfig.SMTP.UserName, config.AppConfig.SMTP.Password, config.AppConfig.SMTP.Sender)
-	movieService := service.NewMovieService(movieRepository, txService)
-	userService := service.NewUserService(userRepository, txService, SMTP, tokenRepository, permissionRepository)
+	movieService := service.NewMovieService(movieRepository, listRepository, txService)
+	userService := service.NewUserService(userRepository, txService, SMTP, tokenRepository, permissionRepository, signer, twoFactorRepository, loginAttemptRepository, apiKeyRepository, accountRepository)
+	personService := service.NewPersonService(personRepository, movieRepository, txService)
+	ratingService := service.NewRatingService(ratingRepository, movieRepository, txService)
+	reviewService := service.NewReviewService(reviewRepository, movieRepository, txService)
+	listService := service.NewListService(listRepository, txService)
+	historyService := service.NewHistoryService(historyRepository, ratingRepository, movieRepository, txService)
+	apiKeyService := service.NewApiKeyService(apiKeyRepository, permissionRepository, txService)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepository, userRepository, txService)
+	permissionService := service.NewPermissionService(permissionRepository, userRepository, txService)
 
 	healthHandler := handlers.NewHealthHandler()
 	movieHandler := handlers.NewMovieHandler(movieService)
 	userHandler := handlers.NewUserHandler(userService)
+	personHandler := handlers.NewPersonHandler(personService)
+	ratingHandler := handlers.NewRatingHandler(ratingService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	listHandler := handlers.NewListHandler(listService)
+	historyHandler := handlers.NewHistoryHandler(historyService)
+	apiKeyHandler := handlers.NewApiKeyHandler(apiKeyService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	permissionHandler := handlers.NewPermissionHandler(permissionService)
 
 	healthCheckRoutes(router, healthHandler)
 	movieRoutes(router, movieHandler, permissionRepository)
-	userRoutes(router, userHandler)
+	userRoutes(router, userHandler, permissionRepository)
+	personRoutes(router, personHandler, permissionRepository)
+	ratingRoutes(router, ratingHandler, permissionRepository)
+	reviewRoutes(router, reviewHandler, permissionRepository)
+	listRoutes(router, listHandler, permissionRepository)
+	historyRoutes(router, historyHandler, permissionRepository)
+	apiKeyRoutes(router, apiKeyHandler)
+	twoFactorRoutes(router, twoFactorHandler)
+	permissionRoutes(router, permissionHandler, permissionRepository)
 
-	return middleware.RecoverPanic(middleware.RateLimit(middleware.Authenticate(userRepository, router)))
+	return middleware.RecoverPanic(middleware.RateLimit(middleware.Authenticate(userRepository, tokenRepository, apiKeyRepository, permissionRepository, txService, signer, router)))
 }
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"net/http"
+)
+
+func reviewRoutes(route *httprouter.Router, handler *handlers.ReviewHandler, permission repository.PermissionRepository) {
+	route.HandlerFunc(http.MethodGet, "/v1/movies/:id/reviews", middleware.RequirePermission(permission, "movies:read", handler.GetReviewsHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/movies/:id/reviews", middleware.RequirePermission(permission, "reviews:write", handler.CreateReviewHandler))
+	route.HandlerFunc(http.MethodPatch, "/v1/movies/:id/reviews/:review_id", middleware.RequirePermission(permission, "reviews:write", handler.UpdateReviewHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/movies/:id/reviews/:review_id", middleware.RequirePermission(permission, "reviews:write", handler.DeleteReviewHandler))
+
+	route.HandlerFunc(http.MethodGet, "/v1/reviews", middleware.RequirePermission(permission, "reviews:moderate", handler.GetModerationQueueHandler))
+	route.HandlerFunc(http.MethodPut, "/v1/reviews/:id/approve", middleware.RequirePermission(permission, "reviews:moderate", handler.ApproveReviewHandler))
+	route.HandlerFunc(http.MethodPut, "/v1/reviews/:id/reject", middleware.RequirePermission(permission, "reviews:moderate", handler.RejectReviewHandler))
+}
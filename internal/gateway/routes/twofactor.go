@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"net/http"
+)
+
+func twoFactorRoutes(route *httprouter.Router, handler *handlers.TwoFactorHandler) {
+	route.HandlerFunc(http.MethodGet, "/v1/me/2fa", middleware.RequireInteractiveUser(handler.GetStatusHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/me/2fa", middleware.RequireInteractiveUser(handler.EnrollHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/me/2fa", middleware.RequireInteractiveUser(handler.DisableHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/me/2fa/confirm", middleware.RequireInteractiveUser(handler.ConfirmHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/me/2fa/recovery-codes", middleware.RequireInteractiveUser(handler.RegenerateRecoveryCodesHandler))
+}
@@ -3,11 +3,34 @@ package routes
 import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
 	"net/http"
 )
 
-func userRoutes(route *httprouter.Router, handler *handlers.UserHandler) {
+func userRoutes(route *httprouter.Router, handler *handlers.UserHandler, permission repository.PermissionRepository) {
 	route.HandlerFunc(http.MethodPost, "/v1/users", handler.RegisterUserHandler)
 	route.HandlerFunc(http.MethodPut, "/v1/users/activated", handler.ActivateUserHandler)
+	route.HandlerFunc(http.MethodPut, "/v1/users/password", handler.ResetPasswordHandler)
 	route.HandlerFunc(http.MethodPost, "/v1/tokens/authentication", handler.CreateAuthenticationTokenHandler)
+	route.HandlerFunc(http.MethodPost, "/v1/tokens/authentication/2fa", handler.CreateTwoFactorAuthenticationTokenHandler)
+	route.HandlerFunc(http.MethodPost, "/v1/tokens/authentication/magic-link", handler.CreateMagicLinkAuthenticationTokenHandler)
+	route.HandlerFunc(http.MethodPost, "/v1/tokens/magic-link", handler.CreateMagicLinkTokenHandler)
+	route.HandlerFunc(http.MethodPost, "/v1/tokens/refresh", handler.RefreshAuthenticationTokenHandler)
+	route.HandlerFunc(http.MethodDelete, "/v1/tokens/authentication", middleware.RequireAuthenticatedUser(handler.RevokeAuthenticationTokenHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/tokens/authentication/all", middleware.RequireInteractiveUser(handler.RevokeAllAuthenticationTokensHandler))
+	route.HandlerFunc(http.MethodPut, "/v1/users/email", handler.ConfirmEmailChangeHandler)
+	route.HandlerFunc(http.MethodGet, "/v1/me", middleware.RequireAuthenticatedUser(handler.GetProfileHandler))
+	route.HandlerFunc(http.MethodPatch, "/v1/me", middleware.RequireInteractiveUser(handler.UpdateProfileHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/me", middleware.RequireInteractiveUser(handler.DeleteAccountHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/me/deletion", middleware.RequireInteractiveUser(handler.CancelAccountDeletionHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/me/export", middleware.RequireInteractiveUser(handler.ExportAccountHandler))
+	route.HandlerFunc(http.MethodPut, "/v1/me/password", middleware.RequireInteractiveUser(handler.ChangePasswordHandler))
+	route.HandlerFunc(http.MethodPut, "/v1/me/email", middleware.RequireInteractiveUser(handler.RequestEmailChangeHandler))
+	route.HandlerFunc(http.MethodGet, "/v1/me/sessions", middleware.RequireInteractiveUser(handler.GetSessionsHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/me/sessions/:id", middleware.RequireInteractiveUser(handler.RevokeSessionHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/users/:id/unlock", middleware.RequirePermission(permission, "users:admin", handler.UnlockUserHandler))
+	route.HandlerFunc(http.MethodDelete, "/v1/users/:id/tokens", middleware.RequirePermission(permission, "users:admin", handler.RevokeUserSessionsHandler))
+	route.HandlerFunc(http.MethodPost, "/v1/tokens/activation", handler.CreateActivationTokenHandler)
+	route.HandlerFunc(http.MethodPost, "/v1/tokens/password-reset", handler.CreatePasswordResetTokenHandler)
 }
@@ -3,9 +3,14 @@ package gateway
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/saleh-ghazimoradi/Cinemaniac/config"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/routes"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/middleware"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/service"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/pkg/jwt"
 	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
 	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
 	"log/slog"
@@ -25,9 +30,81 @@ func Server() error {
 
 	defer db.Close()
 
+	var signer *jwt.Signer
+	if config.AppConfig.Auth.Mode == config.AuthModeSigned {
+		signer, err = jwt.NewSigner(config.AppConfig.Auth.SigningKeys, config.AppConfig.Auth.ActiveKeyID, config.AppConfig.Auth.AccessTokenTTL)
+		if err != nil {
+			return err
+		}
+	}
+
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	purger := service.NewAccountPurger(repository.NewAccountRepository(db, db), repository.NewMovieRepository(db, db), transaction.NewTXService(db))
+
+	service.WG.Add(1)
+	go func() {
+		defer service.WG.Done()
+		purger.Run(backgroundCtx, config.AppConfig.Account.PurgeInterval)
+	}()
+
+	service.WG.Add(1)
+	go func() {
+		defer service.WG.Done()
+		middleware.SweepTouchedCredentials(backgroundCtx)
+	}()
+
+	permissionRepository := repository.NewPermissionRepository(db, db)
+
+	// Every sign-up is given the default role, so a missing one would fail all of them.
+	if _, err = permissionRepository.GetRole(backgroundCtx, config.AppConfig.Auth.DefaultRole); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return fmt.Errorf("default role %q does not exist", config.AppConfig.Auth.DefaultRole)
+		default:
+			return err
+		}
+	}
+
+	permissionCache := repository.NewPermissionCache(permissionRepository, config.AppConfig.Auth.PermissionCacheTTL)
+
+	subscribers := []repository.Subscriber{permissionCache}
+
+	var revocations *service.SessionRevocations
+	if signer != nil {
+		revocations = service.NewSessionRevocations(repository.NewTokenRepository(db, db), signer, transaction.NewTXService(db))
+		subscribers = append(subscribers, revocations)
+	}
+
+	notifications, err := repository.NewNotifications(utils.DBListener(), subscribers...)
+	if err != nil {
+		return err
+	}
+
+	// Revocations are loaded only once their channel is being listened on, so that none
+	// made in between is missed.
+	if revocations != nil {
+		if err = revocations.Load(backgroundCtx); err != nil {
+			return err
+		}
+
+		service.WG.Add(1)
+		go func() {
+			defer service.WG.Done()
+			revocations.Run(backgroundCtx, signer.TTL())
+		}()
+	}
+
+	service.WG.Add(1)
+	go func() {
+		defer service.WG.Done()
+		notifications.Run(backgroundCtx)
+	}()
+
 	server := &http.Server{
 		Addr:         config.AppConfig.Server.Port,
-		Handler:      routes.RegisterRoutes(db),
+		Handler:      routes.RegisterRoutes(db, signer, permissionCache),
 		IdleTimeout:  config.AppConfig.Server.IdleTimeout,
 		ReadTimeout:  config.AppConfig.Server.ReadTimeout,
 		WriteTimeout: config.AppConfig.Server.WriteTimeout,
@@ -54,6 +131,8 @@ func Server() error {
 
 		slg.Logger.Info("completing background tasks", "addr", server.Addr)
 
+		stopBackground()
+
 		service.WG.Wait()
 		shutdownError <- nil
 	}()
@@ -3,7 +3,10 @@ package helper
 import (
 	"fmt"
 	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 func LogError(r *http.Request, err error) {
@@ -87,3 +90,16 @@ func InactiveAccountResponse(w http.ResponseWriter, r *http.Request) {
 	message := "your user account must be activated to access this resource"
 	ErrorResponse(w, r, http.StatusForbidden, message)
 }
+
+// AccountLockedResponse is sent while an account is locked after repeated failed sign-ins.
+func AccountLockedResponse(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	message := "your user account has been temporarily locked due to too many failed sign-in attempts"
+	ErrorResponse(w, r, http.StatusLocked, message)
+}
+
+func LoginThrottledResponse(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	message := "too many failed sign-in attempts, please try again later"
+	ErrorResponse(w, r, http.StatusTooManyRequests, message)
+}
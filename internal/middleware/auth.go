@@ -1,17 +1,21 @@
 package middleware
 
 import (
+	"database/sql"
 	"errors"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/gateway/handlers"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/helper"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"github.com/saleh-ghazimoradi/Cinemaniac/pkg/jwt"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
-func Authenticate(userRepo repository.UserRepository, next http.Handler) http.Handler {
+func Authenticate(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, apiKeyRepo repository.ApiKeyRepository, permissionRepo repository.PermissionRepository, txService transaction.TxService, signer *jwt.Signer, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Vary", "Authorization")
 		authorizationHeader := r.Header.Get("Authorization")
@@ -22,6 +26,22 @@ func Authenticate(userRepo repository.UserRepository, next http.Handler) http.Ha
 		}
 
 		headerParts := strings.Split(authorizationHeader, " ")
+		if len(headerParts) == 2 && headerParts[0] == "ApiKey" {
+			r, err := authenticateApiKey(r, userRepo, apiKeyRepo, permissionRepo, txService, headerParts[1])
+			if err != nil {
+				switch {
+				case errors.Is(err, repository.ErrRecordNotFound):
+					helper.InvalidAuthenticationTokenResponse(w, r)
+				default:
+					helper.ServerErrorResponse(w, r, err)
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
 			helper.InvalidAuthenticationTokenResponse(w, r)
 			return
@@ -29,6 +49,17 @@ func Authenticate(userRepo repository.UserRepository, next http.Handler) http.Ha
 
 		token := headerParts[1]
 
+		if signer != nil && strings.Contains(token, ".") {
+			r, ok := authenticateSigned(r, signer, token)
+			if !ok {
+				helper.InvalidAuthenticationTokenResponse(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		v := validator.New()
 
 		if domain.ValidateTokenPlaintext(v, token); !v.Valid() {
@@ -46,11 +77,83 @@ func Authenticate(userRepo repository.UserRepository, next http.Handler) http.Ha
 			}
 			return
 		}
+		touch(token, func() error {
+			return txService.WithTx(r.Context(), func(tx *sql.Tx) error {
+				return tokenRepo.WithTx(r.Context(), tx).TouchLastUsed(r.Context(), token)
+			})
+		})
+
 		r = handlers.ContextSetUser(r, user)
+		r = handlers.ContextSetToken(r, token)
 		next.ServeHTTP(w, r)
 	})
 }
 
+// authenticateSigned builds the request user and their permissions from the claims of a
+// signed token without consulting the database. The permissions are as of when the token
+// was issued; see issueAuthenticationTokens.
+func authenticateSigned(r *http.Request, signer *jwt.Signer, token string) (*http.Request, bool) {
+	claims, err := signer.Verify(token)
+	if err != nil {
+		return r, false
+	}
+
+	id, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return r, false
+	}
+
+	user := &domain.User{
+		ID:        id,
+		Name:      claims.Name,
+		Activated: claims.Activated,
+	}
+
+	permissions := domain.Permissions(claims.Permissions)
+
+	r = handlers.ContextSetUser(r, user)
+	r = handlers.ContextSetToken(r, token)
+	r = handlers.ContextSetPermissions(r, &permissions)
+
+	return r, true
+}
+
+// authenticateApiKey resolves the key's owner and narrows their permissions to the
+// ones the key was created with.
+func authenticateApiKey(r *http.Request, userRepo repository.UserRepository, apiKeyRepo repository.ApiKeyRepository, permissionRepo repository.PermissionRepository, txService transaction.TxService, plaintext string) (*http.Request, error) {
+	key, err := apiKeyRepo.GetForPlaintext(r.Context(), plaintext)
+	if err != nil {
+		return r, err
+	}
+
+	user, err := userRepo.GetUserById(r.Context(), key.UserID)
+	if err != nil {
+		return r, err
+	}
+
+	permissions, err := permissionRepo.GetAllForUser(r.Context(), user.ID)
+	if err != nil {
+		return r, err
+	}
+
+	if key.Permissions != nil {
+		restricted := permissions.Restrict(key.Permissions)
+		permissions = &restricted
+	}
+
+	touch(plaintext, func() error {
+		return txService.WithTx(r.Context(), func(tx *sql.Tx) error {
+			return apiKeyRepo.WithTx(r.Context(), tx).TouchLastUsed(r.Context(), key.ID)
+		})
+	})
+
+	r = handlers.ContextSetUser(r, user)
+	r = handlers.ContextSetApiKey(r, key)
+	r = handlers.ContextSetPermissions(r, permissions)
+
+	return r, nil
+}
+
 func RequireAuthenticatedUser(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := handlers.ContextGetUser(r)
@@ -79,14 +182,34 @@ func RequireActivatedUser(next http.HandlerFunc) http.HandlerFunc {
 	return RequireAuthenticatedUser(fn)
 }
 
+// RequireInteractiveUser rejects requests authenticated with an API key, so that a key
+// cannot be used to manage the credentials of its owner.
+func RequireInteractiveUser(next http.HandlerFunc) http.HandlerFunc {
+	fn := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := handlers.ContextGetApiKey(r); ok {
+			helper.NotPermittedResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+
+	return RequireActivatedUser(fn)
+}
+
 func RequirePermission(permissionRepo repository.PermissionRepository, code string, next http.HandlerFunc) http.HandlerFunc {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		user := handlers.ContextGetUser(r)
 
-		permissions, err := permissionRepo.GetAllForUser(user.ID)
-		if err != nil {
-			helper.ServerErrorResponse(w, r, err)
-			return
+		permissions, ok := handlers.ContextGetPermissions(r)
+		if !ok {
+			var err error
+			permissions, err = permissionRepo.GetAllForUser(r.Context(), user.ID)
+			if err != nil {
+				helper.ServerErrorResponse(w, r, err)
+				return
+			}
+			r = handlers.ContextSetPermissions(r, permissions)
 		}
 
 		if !permissions.Include(code) {
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+	"sync"
+	"time"
+)
+
+// touchInterval is how stale a credential's last-used time may get before a request
+// refreshes it.
+const touchInterval = 5 * time.Minute
+
+var (
+	touchMu            sync.Mutex
+	touchedCredentials = make(map[[sha256.Size]byte]time.Time)
+)
+
+// touch records that a token or API key has been used by calling write at most once per
+// touchInterval for each credential. The request has already been authenticated, so a
+// failed write is only logged, and retried by the next request.
+func touch(credential string, write func() error) {
+	key := sha256.Sum256([]byte(credential))
+
+	touchMu.Lock()
+	touchedAt := touchedCredentials[key]
+	touchMu.Unlock()
+
+	if time.Since(touchedAt) < touchInterval {
+		return
+	}
+
+	if err := write(); err != nil {
+		slg.Logger.Error("failed to record credential use", "error", err)
+		return
+	}
+
+	touchMu.Lock()
+	touchedCredentials[key] = time.Now()
+	touchMu.Unlock()
+}
+
+// SweepTouchedCredentials forgets credentials that are due to be touched again, every
+// minute until ctx is cancelled.
+func SweepTouchedCredentials(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		touchMu.Lock()
+		for key, touchedAt := range touchedCredentials {
+			if time.Since(touchedAt) > touchInterval {
+				delete(touchedCredentials, key)
+			}
+		}
+		touchMu.Unlock()
+	}
+}
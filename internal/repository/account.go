@@ -0,0 +1,424 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"time"
+)
+
+type AccountRepository interface {
+	GetRatingsForUser(ctx context.Context, userID int64) ([]*domain.Rating, error)
+	GetReviewsForUser(ctx context.Context, userID int64) ([]*domain.Review, error)
+	GetListsWithItemsForUser(ctx context.Context, userID int64) ([]*domain.List, error)
+	GetHistoryForUser(ctx context.Context, userID int64) ([]*domain.HistoryEntry, error)
+	GetDeletion(ctx context.Context, userID int64) (*domain.AccountDeletion, error)
+	ScheduleDeletion(ctx context.Context, deletion *domain.AccountDeletion) error
+	CancelDeletion(ctx context.Context, userID int64) error
+	GetDueDeletions(ctx context.Context, now time.Time, limit int) ([]int64, error)
+	PurgeUser(ctx context.Context, userID int64) ([]int64, error)
+	WithTx(ctx context.Context, tx *sql.Tx) AccountRepository
+}
+
+type accountRepository struct {
+	dbWrite *sql.DB
+	dbRead  *sql.DB
+	tx      *sql.Tx
+}
+
+func (a *accountRepository) GetRatingsForUser(ctx context.Context, userID int64) ([]*domain.Rating, error) {
+	query := `
+        SELECT user_id, movie_id, score, created_at, updated_at
+        FROM ratings
+        WHERE user_id = $1
+        ORDER BY created_at ASC, movie_id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(a.dbRead, a.tx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := []*domain.Rating{}
+
+	for rows.Next() {
+		var rating domain.Rating
+		err = rows.Scan(
+			&rating.UserID,
+			&rating.MovieID,
+			&rating.Score,
+			&rating.CreatedAt,
+			&rating.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		ratings = append(ratings, &rating)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+func (a *accountRepository) GetReviewsForUser(ctx context.Context, userID int64) ([]*domain.Review, error) {
+	query := `
+        SELECT id, created_at, updated_at, movie_id, user_id, title, body, status, moderation_reason, moderated_by, moderated_at, version
+        FROM reviews
+        WHERE user_id = $1
+        ORDER BY id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(a.dbRead, a.tx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []*domain.Review{}
+
+	for rows.Next() {
+		var review domain.Review
+		err = rows.Scan(
+			&review.ID,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+			&review.MovieID,
+			&review.UserID,
+			&review.Title,
+			&review.Body,
+			&review.Status,
+			&review.ModerationReason,
+			&review.ModeratedBy,
+			&review.ModeratedAt,
+			&review.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		reviews = append(reviews, &review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// GetListsWithItemsForUser returns all of the user's lists whatever their visibility, each
+// with its items in list order.
+func (a *accountRepository) GetListsWithItemsForUser(ctx context.Context, userID int64) ([]*domain.List, error) {
+	query := `
+        SELECT lists.id, lists.created_at, lists.updated_at, lists.user_id, lists.name, lists.description, lists.visibility, lists.slug, lists.version,
+            list_items.movie_id, movies.title, movies.year, list_items.position, list_items.added_at
+        FROM lists
+        LEFT JOIN list_items ON list_items.list_id = lists.id
+        LEFT JOIN movies ON movies.id = list_items.movie_id
+        WHERE lists.user_id = $1
+        ORDER BY lists.id ASC, list_items.position ASC, list_items.added_at ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(a.dbRead, a.tx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := []*domain.List{}
+
+	for rows.Next() {
+		var list domain.List
+		var movieID sql.NullInt64
+		var title sql.NullString
+		var year, position sql.NullInt32
+		var addedAt sql.NullTime
+
+		err = rows.Scan(
+			&list.ID,
+			&list.CreatedAt,
+			&list.UpdatedAt,
+			&list.UserID,
+			&list.Name,
+			&list.Description,
+			&list.Visibility,
+			&list.Slug,
+			&list.Version,
+			&movieID,
+			&title,
+			&year,
+			&position,
+			&addedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(lists) == 0 || lists[len(lists)-1].ID != list.ID {
+			list.Items = []*domain.ListItem{}
+			lists = append(lists, &list)
+		}
+
+		if movieID.Valid {
+			current := lists[len(lists)-1]
+			current.Items = append(current.Items, &domain.ListItem{
+				MovieID:  movieID.Int64,
+				Title:    title.String,
+				Year:     year.Int32,
+				Position: position.Int32,
+				AddedAt:  addedAt.Time,
+			})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+func (a *accountRepository) GetHistoryForUser(ctx context.Context, userID int64) ([]*domain.HistoryEntry, error) {
+	query := `
+        SELECT watch_history.id, watch_history.user_id, watch_history.movie_id, movies.title, movies.year, movies.runtime,
+            watch_history.watched_at, COALESCE(ratings.score, 0)
+        FROM watch_history
+        INNER JOIN movies ON movies.id = watch_history.movie_id
+        LEFT JOIN ratings ON ratings.user_id = watch_history.user_id AND ratings.movie_id = watch_history.movie_id
+        WHERE watch_history.user_id = $1
+        ORDER BY watch_history.watched_at ASC, watch_history.id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(a.dbRead, a.tx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*domain.HistoryEntry{}
+
+	for rows.Next() {
+		var entry domain.HistoryEntry
+		err = rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.MovieID,
+			&entry.Title,
+			&entry.Year,
+			&entry.Runtime,
+			&entry.WatchedAt,
+			&entry.Rating,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (a *accountRepository) GetDeletion(ctx context.Context, userID int64) (*domain.AccountDeletion, error) {
+	query := `
+        SELECT user_id, requested_at, purge_after
+        FROM account_deletions
+        WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	deletion := &domain.AccountDeletion{}
+
+	err := exec(a.dbRead, a.tx).QueryRowContext(ctx, query, userID).Scan(&deletion.UserID, &deletion.RequestedAt, &deletion.PurgeAfter)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return deletion, nil
+}
+
+// ScheduleDeletion records a deletion request. It fails with ErrEditConflict if one is
+// already pending, so the original purge date is never pushed back.
+func (a *accountRepository) ScheduleDeletion(ctx context.Context, deletion *domain.AccountDeletion) error {
+	query := `
+        INSERT INTO account_deletions (user_id, purge_after)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id) DO NOTHING
+        RETURNING requested_at`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(a.dbWrite, a.tx).QueryRowContext(ctx, query, deletion.UserID, deletion.PurgeAfter).Scan(&deletion.RequestedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *accountRepository) CancelDeletion(ctx context.Context, userID int64) error {
+	query := `
+        DELETE FROM account_deletions
+        WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(a.dbWrite, a.tx).ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (a *accountRepository) GetDueDeletions(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	query := `
+        SELECT user_id
+        FROM account_deletions
+        WHERE purge_after <= $1
+        ORDER BY purge_after ASC
+        LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(a.dbRead, a.tx).QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := []int64{}
+
+	for rows.Next() {
+		var userID int64
+		if err = rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+// PurgeUser hard-deletes a user whose deletion is due, failing with ErrRecordNotFound if
+// the request has been cancelled in the meantime. Everything the user owns goes with the
+// ON DELETE CASCADE relations. It returns the movies the user had rated, whose aggregates
+// the caller must recalculate in the same transaction.
+func (a *accountRepository) PurgeUser(ctx context.Context, userID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	query := `
+        SELECT user_id
+        FROM account_deletions
+        WHERE user_id = $1 AND purge_after <= NOW()
+        FOR UPDATE`
+
+	if err := exec(a.dbWrite, a.tx).QueryRowContext(ctx, query, userID).Scan(&userID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	query = `
+        SELECT movie_id
+        FROM ratings
+        WHERE user_id = $1
+        ORDER BY movie_id`
+
+	rows, err := exec(a.dbWrite, a.tx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movieIDs := []int64{}
+
+	for rows.Next() {
+		var movieID int64
+		if err = rows.Scan(&movieID); err != nil {
+			return nil, err
+		}
+
+		movieIDs = append(movieIDs, movieID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+        DELETE FROM users
+        WHERE id = $1`
+
+	if _, err = exec(a.dbWrite, a.tx).ExecContext(ctx, query, userID); err != nil {
+		return nil, err
+	}
+
+	return movieIDs, nil
+}
+
+func (a *accountRepository) WithTx(ctx context.Context, tx *sql.Tx) AccountRepository {
+	return &accountRepository{
+		dbWrite: a.dbWrite,
+		dbRead:  a.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewAccountRepository(dbWrite, dbRead *sql.DB) AccountRepository {
+	return &accountRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
+	"time"
+)
+
+type ApiKeyRepository interface {
+	CreateApiKey(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, error)
+	GetApiKeyById(ctx context.Context, userID, id int64) (*domain.ApiKey, error)
+	GetApiKeysForUser(ctx context.Context, userID int64) ([]*domain.ApiKey, error)
+	GetForPlaintext(ctx context.Context, plaintext string) (*domain.ApiKey, error)
+	UpdateApiKey(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, error)
+	DeleteApiKey(ctx context.Context, userID, id int64) error
+	TouchLastUsed(ctx context.Context, id int64) error
+	WithTx(ctx context.Context, tx *sql.Tx) ApiKeyRepository
+}
+
+type apiKeyRepository struct {
+	dbWrite *sql.DB
+	dbRead  *sql.DB
+	tx      *sql.Tx
+}
+
+func (a *apiKeyRepository) CreateApiKey(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, error) {
+	query := `
+        INSERT INTO api_keys (user_id, name, hash, permissions, expiry)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`
+
+	args := []any{key.UserID, key.Name, key.Hash, pq.Array(key.Permissions), key.Expiry}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	if err := exec(a.dbWrite, a.tx).QueryRowContext(ctx, query, args...).Scan(&key.ID, &key.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (a *apiKeyRepository) GetApiKeyById(ctx context.Context, userID, id int64) (*domain.ApiKey, error) {
+	query := `
+        SELECT id, created_at, user_id, name, permissions, expiry, last_used_at
+        FROM api_keys
+        WHERE id = $1 AND user_id = $2`
+
+	return a.getApiKey(ctx, query, id, userID)
+}
+
+// GetForPlaintext finds the unexpired key matching plaintext.
+func (a *apiKeyRepository) GetForPlaintext(ctx context.Context, plaintext string) (*domain.ApiKey, error) {
+	query := `
+        SELECT id, created_at, user_id, name, permissions, expiry, last_used_at
+        FROM api_keys
+        WHERE hash = $1 AND (expiry IS NULL OR expiry > $2)`
+
+	return a.getApiKey(ctx, query, utils.HashToken(plaintext), time.Now())
+}
+
+func (a *apiKeyRepository) getApiKey(ctx context.Context, query string, args ...any) (*domain.ApiKey, error) {
+	key := &domain.ApiKey{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(a.dbRead, a.tx).QueryRowContext(ctx, query, args...).Scan(
+		&key.ID,
+		&key.CreatedAt,
+		&key.UserID,
+		&key.Name,
+		pq.Array(&key.Permissions),
+		&key.Expiry,
+		&key.LastUsedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+func (a *apiKeyRepository) GetApiKeysForUser(ctx context.Context, userID int64) ([]*domain.ApiKey, error) {
+	query := `
+        SELECT id, created_at, user_id, name, permissions, expiry, last_used_at
+        FROM api_keys
+        WHERE user_id = $1
+        ORDER BY id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(a.dbRead, a.tx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*domain.ApiKey{}
+
+	for rows.Next() {
+		var key domain.ApiKey
+		err = rows.Scan(
+			&key.ID,
+			&key.CreatedAt,
+			&key.UserID,
+			&key.Name,
+			pq.Array(&key.Permissions),
+			&key.Expiry,
+			&key.LastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, &key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (a *apiKeyRepository) UpdateApiKey(ctx context.Context, key *domain.ApiKey) (*domain.ApiKey, error) {
+	query := `
+        UPDATE api_keys
+        SET name = $1, permissions = $2, expiry = $3
+        WHERE id = $4 AND user_id = $5`
+
+	args := []any{key.Name, pq.Array(key.Permissions), key.Expiry, key.ID, key.UserID}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(a.dbWrite, a.tx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	return key, nil
+}
+
+func (a *apiKeyRepository) DeleteApiKey(ctx context.Context, userID, id int64) error {
+	query := `DELETE FROM api_keys WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(a.dbWrite, a.tx).ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (a *apiKeyRepository) TouchLastUsed(ctx context.Context, id int64) error {
+	query := `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(a.dbWrite, a.tx).ExecContext(ctx, query, id)
+	return err
+}
+
+func (a *apiKeyRepository) WithTx(ctx context.Context, tx *sql.Tx) ApiKeyRepository {
+	return &apiKeyRepository{
+		dbWrite: a.dbWrite,
+		dbRead:  a.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewApiKeyRepository(dbWrite, dbRead *sql.DB) ApiKeyRepository {
+	return &apiKeyRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
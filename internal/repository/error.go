@@ -3,7 +3,11 @@ package repository
 import "errors"
 
 var (
-	ErrRecordNotFound = errors.New("record not found")
-	ErrEditConflict   = errors.New("edit conflict")
-	ErrDuplicateEmail = errors.New("duplicate email")
+	ErrRecordNotFound  = errors.New("record not found")
+	ErrEditConflict    = errors.New("edit conflict")
+	ErrDuplicateEmail  = errors.New("duplicate email")
+	ErrDuplicateReview = errors.New("duplicate review")
+	ErrDuplicateItem   = errors.New("duplicate item")
+	ErrDuplicateCode   = errors.New("duplicate permission code")
+	ErrDuplicateRole   = errors.New("duplicate role")
 )
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+)
+
+type HistoryRepository interface {
+	CreateEntry(ctx context.Context, entry *domain.HistoryEntry) (*domain.HistoryEntry, error)
+	GetEntries(ctx context.Context, userID int64, filters domain.Filters) ([]*domain.HistoryEntry, domain.Metadata, error)
+	DeleteEntry(ctx context.Context, userID, id int64) error
+	GetStats(ctx context.Context, userID int64) (*domain.WatchStats, error)
+	WithTx(ctx context.Context, tx *sql.Tx) HistoryRepository
+}
+
+type historyRepository struct {
+	dbWrite *sql.DB
+	dbRead  *sql.DB
+	tx      *sql.Tx
+}
+
+func (h *historyRepository) CreateEntry(ctx context.Context, entry *domain.HistoryEntry) (*domain.HistoryEntry, error) {
+	query := `
+        INSERT INTO watch_history (user_id, movie_id, watched_at)
+        VALUES ($1, $2, $3)
+        RETURNING id`
+
+	args := []any{entry.UserID, entry.MovieID, entry.WatchedAt}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(h.dbWrite, h.tx).QueryRowContext(ctx, query, args...).Scan(&entry.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return entry, nil
+}
+
+func (h *historyRepository) GetEntries(ctx context.Context, userID int64, filters domain.Filters) ([]*domain.HistoryEntry, domain.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), watch_history.id, watch_history.user_id, watch_history.movie_id, movies.title, movies.year, movies.runtime,
+            watch_history.watched_at, COALESCE(ratings.score, 0)
+        FROM watch_history
+        INNER JOIN movies ON movies.id = watch_history.movie_id
+        LEFT JOIN ratings ON ratings.user_id = watch_history.user_id AND ratings.movie_id = watch_history.movie_id
+        WHERE watch_history.user_id = $1
+        ORDER BY watch_history.%s %s, watch_history.id ASC
+        LIMIT $2 OFFSET $3`, filters.SortColumn(), filters.SortDirection())
+
+	args := []any{userID, filters.Limit(), filters.Offset()}
+
+	rows, err := exec(h.dbRead, h.tx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, domain.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	entries := []*domain.HistoryEntry{}
+
+	for rows.Next() {
+		var entry domain.HistoryEntry
+		err = rows.Scan(
+			&totalRecords,
+			&entry.ID,
+			&entry.UserID,
+			&entry.MovieID,
+			&entry.Title,
+			&entry.Year,
+			&entry.Runtime,
+			&entry.WatchedAt,
+			&entry.Rating,
+		)
+		if err != nil {
+			return nil, domain.Metadata{}, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.CalculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return entries, metadata, nil
+}
+
+func (h *historyRepository) DeleteEntry(ctx context.Context, userID, id int64) error {
+	query := `DELETE FROM watch_history WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(h.dbWrite, h.tx).ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (h *historyRepository) GetStats(ctx context.Context, userID int64) (*domain.WatchStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	conn := exec(h.dbRead, h.tx)
+
+	stats := &domain.WatchStats{
+		TopGenres:     []domain.GenreCount{},
+		MoviesPerYear: []domain.YearCount{},
+	}
+
+	query := `
+        SELECT count(*), COALESCE(sum(movies.runtime), 0)
+        FROM watch_history
+        INNER JOIN movies ON movies.id = watch_history.movie_id
+        WHERE watch_history.user_id = $1`
+
+	if err := conn.QueryRowContext(ctx, query, userID).Scan(&stats.TotalWatched, &stats.TotalRuntime); err != nil {
+		return nil, err
+	}
+
+	query = `
+        SELECT genre, count(*)
+        FROM watch_history
+        INNER JOIN movies ON movies.id = watch_history.movie_id
+        CROSS JOIN unnest(movies.genres) AS genre
+        WHERE watch_history.user_id = $1
+        GROUP BY genre
+        ORDER BY count(*) DESC, genre ASC
+        LIMIT 5`
+
+	rows, err := conn.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var genre domain.GenreCount
+		if err = rows.Scan(&genre.Genre, &genre.Count); err != nil {
+			return nil, err
+		}
+		stats.TopGenres = append(stats.TopGenres, genre)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+        SELECT EXTRACT(YEAR FROM watched_at)::integer AS year, count(*)
+        FROM watch_history
+        WHERE user_id = $1
+        GROUP BY year
+        ORDER BY year ASC`
+
+	yearRows, err := conn.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer yearRows.Close()
+
+	for yearRows.Next() {
+		var year domain.YearCount
+		if err = yearRows.Scan(&year.Year, &year.Count); err != nil {
+			return nil, err
+		}
+		stats.MoviesPerYear = append(stats.MoviesPerYear, year)
+	}
+
+	if err = yearRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (h *historyRepository) WithTx(ctx context.Context, tx *sql.Tx) HistoryRepository {
+	return &historyRepository{
+		dbWrite: h.dbWrite,
+		dbRead:  h.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewHistoryRepository(dbWrite, dbRead *sql.DB) HistoryRepository {
+	return &historyRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+)
+
+type ListRepository interface {
+	CreateList(ctx context.Context, list *domain.List) (*domain.List, error)
+	GetListById(ctx context.Context, id int64) (*domain.List, error)
+	GetListBySlug(ctx context.Context, slug string) (*domain.List, error)
+	GetListsForUser(ctx context.Context, userID int64, visibility string, filters domain.Filters) ([]*domain.List, domain.Metadata, error)
+	UpdateList(ctx context.Context, list *domain.List) (*domain.List, error)
+	DeleteList(ctx context.Context, id int64) error
+	GetItems(ctx context.Context, listID int64) ([]*domain.ListItem, error)
+	AddItem(ctx context.Context, listID, movieID int64) error
+	RemoveItem(ctx context.Context, listID, movieID int64) error
+	SetPositions(ctx context.Context, listID int64, movieIDs []int64) error
+	DeleteItemsForMovie(ctx context.Context, movieID int64) error
+	WithTx(ctx context.Context, tx *sql.Tx) ListRepository
+}
+
+type listRepository struct {
+	dbWrite *sql.DB
+	dbRead  *sql.DB
+	tx      *sql.Tx
+}
+
+func (l *listRepository) CreateList(ctx context.Context, list *domain.List) (*domain.List, error) {
+	query := `
+        INSERT INTO lists (user_id, name, description, visibility, slug)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at, updated_at, version`
+
+	args := []any{list.UserID, list.Name, list.Description, list.Visibility, list.Slug}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(l.dbWrite, l.tx).QueryRowContext(ctx, query, args...).Scan(&list.ID, &list.CreatedAt, &list.UpdatedAt, &list.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (l *listRepository) GetListById(ctx context.Context, id int64) (*domain.List, error) {
+	query := `
+        SELECT id, created_at, updated_at, user_id, name, description, visibility, slug, version
+        FROM lists
+        WHERE id = $1`
+
+	return l.getList(ctx, query, id)
+}
+
+func (l *listRepository) GetListBySlug(ctx context.Context, slug string) (*domain.List, error) {
+	query := `
+        SELECT id, created_at, updated_at, user_id, name, description, visibility, slug, version
+        FROM lists
+        WHERE slug = $1`
+
+	return l.getList(ctx, query, slug)
+}
+
+func (l *listRepository) getList(ctx context.Context, query string, arg any) (*domain.List, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	list := &domain.List{}
+
+	if err := exec(l.dbRead, l.tx).QueryRowContext(ctx, query, arg).Scan(
+		&list.ID,
+		&list.CreatedAt,
+		&list.UpdatedAt,
+		&list.UserID,
+		&list.Name,
+		&list.Description,
+		&list.Visibility,
+		&list.Slug,
+		&list.Version,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+// GetListsForUser returns the lists owned by userID. An empty visibility returns lists of
+// every visibility.
+func (l *listRepository) GetListsForUser(ctx context.Context, userID int64, visibility string, filters domain.Filters) ([]*domain.List, domain.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), id, created_at, updated_at, user_id, name, description, visibility, slug, version
+        FROM lists
+        WHERE user_id = $1
+        AND (visibility = $2 OR $2 = '')
+        ORDER BY %s %s, id ASC
+        LIMIT $3 OFFSET $4`, filters.SortColumn(), filters.SortDirection())
+
+	args := []any{userID, visibility, filters.Limit(), filters.Offset()}
+
+	rows, err := exec(l.dbRead, l.tx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, domain.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	lists := []*domain.List{}
+
+	for rows.Next() {
+		var list domain.List
+		err = rows.Scan(
+			&totalRecords,
+			&list.ID,
+			&list.CreatedAt,
+			&list.UpdatedAt,
+			&list.UserID,
+			&list.Name,
+			&list.Description,
+			&list.Visibility,
+			&list.Slug,
+			&list.Version,
+		)
+		if err != nil {
+			return nil, domain.Metadata{}, err
+		}
+
+		lists = append(lists, &list)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.CalculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return lists, metadata, nil
+}
+
+func (l *listRepository) UpdateList(ctx context.Context, list *domain.List) (*domain.List, error) {
+	query := `
+        UPDATE lists
+        SET name = $1, description = $2, visibility = $3, updated_at = NOW(), version = version + 1
+        WHERE id = $4 AND version = $5
+        RETURNING updated_at, version`
+
+	args := []any{
+		list.Name,
+		list.Description,
+		list.Visibility,
+		list.ID,
+		list.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	if err := exec(l.dbWrite, l.tx).QueryRowContext(ctx, query, args...).Scan(&list.UpdatedAt, &list.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+func (l *listRepository) DeleteList(ctx context.Context, id int64) error {
+	query := `DELETE FROM lists WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(l.dbWrite, l.tx).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (l *listRepository) GetItems(ctx context.Context, listID int64) ([]*domain.ListItem, error) {
+	query := `
+        SELECT list_items.movie_id, movies.title, movies.year, list_items.position, list_items.added_at
+        FROM list_items
+        INNER JOIN movies ON movies.id = list_items.movie_id
+        WHERE list_items.list_id = $1
+        ORDER BY list_items.position ASC, list_items.added_at ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(l.dbRead, l.tx).QueryContext(ctx, query, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*domain.ListItem{}
+
+	for rows.Next() {
+		var item domain.ListItem
+		err = rows.Scan(
+			&item.MovieID,
+			&item.Title,
+			&item.Year,
+			&item.Position,
+			&item.AddedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// AddItem appends the movie to the end of the list.
+func (l *listRepository) AddItem(ctx context.Context, listID, movieID int64) error {
+	query := `
+        INSERT INTO list_items (list_id, movie_id, position)
+        SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM list_items WHERE list_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(l.dbWrite, l.tx).ExecContext(ctx, query, listID, movieID)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23505":
+			return ErrDuplicateItem
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *listRepository) RemoveItem(ctx context.Context, listID, movieID int64) error {
+	query := `DELETE FROM list_items WHERE list_id = $1 AND movie_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(l.dbWrite, l.tx).ExecContext(ctx, query, listID, movieID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetPositions renumbers the items of the list following the order of movieIDs.
+func (l *listRepository) SetPositions(ctx context.Context, listID int64, movieIDs []int64) error {
+	query := `
+        UPDATE list_items
+        SET position = ordering.position
+        FROM unnest($2::bigint[]) WITH ORDINALITY AS ordering(movie_id, position)
+        WHERE list_items.list_id = $1 AND list_items.movie_id = ordering.movie_id`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(l.dbWrite, l.tx).ExecContext(ctx, query, listID, pq.Array(movieIDs))
+	return err
+}
+
+func (l *listRepository) DeleteItemsForMovie(ctx context.Context, movieID int64) error {
+	query := `DELETE FROM list_items WHERE movie_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(l.dbWrite, l.tx).ExecContext(ctx, query, movieID)
+	return err
+}
+
+func (l *listRepository) WithTx(ctx context.Context, tx *sql.Tx) ListRepository {
+	return &listRepository{
+		dbWrite: l.dbWrite,
+		dbRead:  l.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewListRepository(dbWrite, dbRead *sql.DB) ListRepository {
+	return &listRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	GetAttempts(ctx context.Context, key string) (*domain.LoginAttempts, error)
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempts, error)
+	Block(ctx context.Context, key string, until time.Time, locked bool) error
+	Reset(ctx context.Context, key string) error
+	WithTx(ctx context.Context, tx *sql.Tx) LoginAttemptRepository
+}
+
+type loginAttemptRepository struct {
+	dbWrite *sql.DB
+	dbRead  *sql.DB
+	tx      *sql.Tx
+}
+
+// GetAttempts returns an empty record for keys without recent failures.
+func (l *loginAttemptRepository) GetAttempts(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	query := `
+        SELECT key, failures, blocked_until, locked
+        FROM login_attempts
+        WHERE key = $1`
+
+	attempts := &domain.LoginAttempts{Key: key}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(l.dbRead, l.tx).QueryRowContext(ctx, query, key).Scan(
+		&attempts.Key,
+		&attempts.Failures,
+		&attempts.BlockedUntil,
+		&attempts.Locked,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// RecordFailure counts a failed attempt, starting over if the previous failure is older
+// than window.
+func (l *loginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempts, error) {
+	query := `
+        INSERT INTO login_attempts (key, failures, last_failure_at)
+        VALUES ($1, 1, NOW())
+        ON CONFLICT (key) DO UPDATE
+        SET failures = CASE
+                WHEN login_attempts.last_failure_at < NOW() - make_interval(secs => $2) THEN 1
+                ELSE login_attempts.failures + 1
+            END,
+            last_failure_at = NOW()
+        RETURNING key, failures, blocked_until, locked`
+
+	attempts := &domain.LoginAttempts{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(l.dbWrite, l.tx).QueryRowContext(ctx, query, key, window.Seconds()).Scan(
+		&attempts.Key,
+		&attempts.Failures,
+		&attempts.BlockedUntil,
+		&attempts.Locked,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+func (l *loginAttemptRepository) Block(ctx context.Context, key string, until time.Time, locked bool) error {
+	query := `
+        UPDATE login_attempts
+        SET blocked_until = $2, locked = $3
+        WHERE key = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(l.dbWrite, l.tx).ExecContext(ctx, query, key, until, locked)
+	return err
+}
+
+func (l *loginAttemptRepository) Reset(ctx context.Context, key string) error {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(l.dbWrite, l.tx).ExecContext(ctx, query, key)
+	return err
+}
+
+func (l *loginAttemptRepository) WithTx(ctx context.Context, tx *sql.Tx) LoginAttemptRepository {
+	return &loginAttemptRepository{
+		dbWrite: l.dbWrite,
+		dbRead:  l.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewLoginAttemptRepository(dbWrite, dbRead *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
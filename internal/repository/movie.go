@@ -4,20 +4,42 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/lib/pq"
 	"github.com/saleh-ghazimoradi/Cinemaniac/config"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
+	"html"
+	"slices"
+	"strings"
 )
 
 type MovieRepository interface {
 	CreateMovie(ctx context.Context, movie *domain.Movie) (*domain.Movie, error)
 	GetMovieById(ctx context.Context, id int64) (*domain.Movie, error)
-	GetMovies(ctx context.Context) ([]*domain.Movie, error)
+	GetMovies(ctx context.Context, title string, genres []string, filters domain.Filters) ([]*domain.Movie, domain.Metadata, error)
+	GetMoviesByCursor(ctx context.Context, title string, genres []string, filters domain.Filters, cursor *domain.Cursor) ([]*domain.Movie, bool, error)
+	SearchMovies(ctx context.Context, query, mode string, filters domain.Filters) ([]*domain.MovieSearchResult, domain.Metadata, error)
 	UpdateMovie(ctx context.Context, movie *domain.Movie) (*domain.Movie, error)
+	RecalculateRating(ctx context.Context, id int64) error
 	DeleteMovie(ctx context.Context, id int64) error
 	WithTx(ctx context.Context, tx *sql.Tx) MovieRepository
 }
 
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+var headlineReplacer = strings.NewReplacer(headlineStart, "<b>", headlineStop, "</b>")
+
+var movieColumnTypes = map[string]string{
+	"id":      "bigint",
+	"title":   "text",
+	"year":    "integer",
+	"runtime": "integer",
+}
+
 type movieRepository struct {
 	dbWrite *sql.DB
 	dbRead  *sql.DB
@@ -26,11 +48,11 @@ type movieRepository struct {
 
 func (m *movieRepository) CreateMovie(ctx context.Context, movie *domain.Movie) (*domain.Movie, error) {
 	query := `
-        INSERT INTO movies (title, year, runtime, genres) 
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO movies (title, year, runtime, genres, created_by) 
+        VALUES ($1, $2, $3, $4, $5)
         RETURNING id, created_at, version`
 
-	args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres)}
+	args := []any{movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.CreatedBy}
 
 	err := exec(m.dbWrite, m.tx).QueryRowContext(ctx, query, args...).Scan(&movie.ID, &movie.CreatedAt, &movie.Version)
 	if err != nil {
@@ -45,7 +67,7 @@ func (m *movieRepository) GetMovieById(ctx context.Context, id int64) (*domain.M
 	defer cancel()
 
 	query := `
-        SELECT id, created_at, title, year, runtime, genres, version
+        SELECT id, created_at, title, year, runtime, genres, ROUND(COALESCE(rating_sum::numeric / NULLIF(rating_count, 0), 0), 2)::float8, rating_count, version, created_by
         FROM movies
         WHERE id = $1`
 
@@ -58,7 +80,10 @@ func (m *movieRepository) GetMovieById(ctx context.Context, id int64) (*domain.M
 		&movie.Year,
 		&movie.Runtime,
 		pq.Array(&movie.Genres),
+		&movie.RatingAverage,
+		&movie.RatingCount,
 		&movie.Version,
+		&movie.CreatedBy,
 	); err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -71,42 +96,212 @@ func (m *movieRepository) GetMovieById(ctx context.Context, id int64) (*domain.M
 	return movie, nil
 }
 
-func (m *movieRepository) GetMovies(ctx context.Context) ([]*domain.Movie, error) {
+func (m *movieRepository) GetMovies(ctx context.Context, title string, genres []string, filters domain.Filters) ([]*domain.Movie, domain.Metadata, error) {
 	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
 	defer cancel()
 
-	var movies []*domain.Movie
-	query := `SELECT id, created_at, title, year, runtime, genres, version FROM movies`
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), id, created_at, title, year, runtime, genres, ROUND(COALESCE(rating_sum::numeric / NULLIF(rating_count, 0), 0), 2)::float8, rating_count, version
+        FROM movies
+        WHERE (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR $1 = '')
+        AND (genres @> $2 OR $2 = '{}')
+        ORDER BY %s %s, id ASC
+        LIMIT $3 OFFSET $4`, filters.SortColumn(), filters.SortDirection())
+
+	args := []any{title, pq.Array(genres), filters.Limit(), filters.Offset()}
 
-	rows, err := exec(m.dbRead, m.tx).QueryContext(ctx, query)
+	rows, err := exec(m.dbRead, m.tx).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, domain.Metadata{}, err
 	}
 	defer rows.Close()
 
+	totalRecords := 0
+	movies := []*domain.Movie{}
+
 	for rows.Next() {
 		var movie domain.Movie
 		err = rows.Scan(
+			&totalRecords,
 			&movie.ID,
 			&movie.CreatedAt,
 			&movie.Title,
 			&movie.Year,
 			&movie.Runtime,
 			pq.Array(&movie.Genres),
+			&movie.RatingAverage,
+			&movie.RatingCount,
 			&movie.Version,
 		)
 		if err != nil {
-			return nil, err
+			return nil, domain.Metadata{}, err
 		}
 
 		movies = append(movies, &movie)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, domain.Metadata{}, err
 	}
 
-	return movies, nil
+	metadata := domain.CalculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return movies, metadata, nil
+}
+
+// GetMoviesByCursor returns up to filters.PageSize movies following (or, for a backward
+// cursor, preceding) the cursor position, and whether more rows exist in that direction.
+func (m *movieRepository) GetMoviesByCursor(ctx context.Context, title string, genres []string, filters domain.Filters, cursor *domain.Cursor) ([]*domain.Movie, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	column := filters.SortColumn()
+	direction := filters.SortDirection()
+
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		direction = map[string]string{"ASC": "DESC", "DESC": "ASC"}[direction]
+	}
+
+	comparison := ">"
+	if direction == "DESC" {
+		comparison = "<"
+	}
+
+	args := []any{title, pq.Array(genres), filters.Limit() + 1}
+
+	keyset := ""
+	if cursor != nil {
+		keyset = fmt.Sprintf("AND (%s, id) %s ($4::%s, $5)", column, comparison, movieColumnTypes[column])
+		args = append(args, cursor.Value, cursor.ID)
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, created_at, title, year, runtime, genres, ROUND(COALESCE(rating_sum::numeric / NULLIF(rating_count, 0), 0), 2)::float8, rating_count, version
+        FROM movies
+        WHERE (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR $1 = '')
+        AND (genres @> $2 OR $2 = '{}')
+        %s
+        ORDER BY %s %s, id %s
+        LIMIT $3`, keyset, column, direction, direction)
+
+	rows, err := exec(m.dbRead, m.tx).QueryContext(ctx, query, args...)
+	if err != nil {
+		// The cursor is signed, but a value that does not fit the sort column must not
+		// become a server error.
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && (pqErr.Code == "22P02" || pqErr.Code == "22003"):
+			return nil, false, utils.ErrInvalidCursor
+		default:
+			return nil, false, err
+		}
+	}
+	defer rows.Close()
+
+	movies := []*domain.Movie{}
+
+	for rows.Next() {
+		var movie domain.Movie
+		err = rows.Scan(
+			&movie.ID,
+			&movie.CreatedAt,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.RatingAverage,
+			&movie.RatingCount,
+			&movie.Version,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+
+		movies = append(movies, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(movies) > filters.Limit()
+	if hasMore {
+		movies = movies[:filters.Limit()]
+	}
+
+	if backward {
+		slices.Reverse(movies)
+	}
+
+	return movies, hasMore, nil
+}
+
+func (m *movieRepository) SearchMovies(ctx context.Context, query, mode string, filters domain.Filters) ([]*domain.MovieSearchResult, domain.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	tsQuery := "plainto_tsquery"
+	if mode == domain.SearchModeWebsearch {
+		tsQuery = "websearch_to_tsquery"
+	}
+
+	stmt := fmt.Sprintf(`
+        SELECT count(*) OVER(), id, created_at, title, year, runtime, genres, ROUND(COALESCE(rating_sum::numeric / NULLIF(rating_count, 0), 0), 2)::float8, rating_count, version,
+            ts_rank(to_tsvector('simple', title), query) AS rank,
+            ts_headline('simple', translate(title, $4, ''), query, $5) AS headline
+        FROM movies, %s('simple', $1) query
+        WHERE to_tsvector('simple', title) @@ query
+        ORDER BY rank DESC, id ASC
+        LIMIT $2 OFFSET $3`, tsQuery)
+
+	// Matches are marked with control characters rather than tags, so that the title can be
+	// escaped before they are turned into <b> elements; any in the title itself are dropped.
+	headlineOptions := fmt.Sprintf(`StartSel="%s", StopSel="%s", HighlightAll=true`, headlineStart, headlineStop)
+
+	args := []any{query, filters.Limit(), filters.Offset(), headlineStart + headlineStop, headlineOptions}
+
+	rows, err := exec(m.dbRead, m.tx).QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, domain.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	results := []*domain.MovieSearchResult{}
+
+	for rows.Next() {
+		result := domain.MovieSearchResult{Movie: &domain.Movie{}}
+		err = rows.Scan(
+			&totalRecords,
+			&result.ID,
+			&result.CreatedAt,
+			&result.Title,
+			&result.Year,
+			&result.Runtime,
+			pq.Array(&result.Genres),
+			&result.RatingAverage,
+			&result.RatingCount,
+			&result.Version,
+			&result.Rank,
+			&result.Headline,
+		)
+		if err != nil {
+			return nil, domain.Metadata{}, err
+		}
+
+		result.Headline = headlineReplacer.Replace(html.EscapeString(result.Headline))
+
+		results = append(results, &result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.CalculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return results, metadata, nil
 }
 
 func (m *movieRepository) UpdateMovie(ctx context.Context, movie *domain.Movie) (*domain.Movie, error) {
@@ -141,6 +336,43 @@ func (m *movieRepository) UpdateMovie(ctx context.Context, movie *domain.Movie)
 	return movie, nil
 }
 
+// RecalculateRating recomputes the denormalised rating aggregate of a movie from its
+// ratings. The movie row is locked first so that concurrent rating changes are applied one
+// at a time and each recount sees the ones committed before it. It does not touch the
+// version column, since ratings are not edits to the movie itself.
+func (m *movieRepository) RecalculateRating(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	query := `
+        SELECT id
+        FROM movies
+        WHERE id = $1
+        FOR UPDATE`
+
+	if err := exec(m.dbWrite, m.tx).QueryRowContext(ctx, query, id).Scan(&id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	query = `
+        UPDATE movies
+        SET rating_count = aggregate.count, rating_sum = aggregate.sum
+        FROM (
+            SELECT COUNT(*) AS count, COALESCE(SUM(score), 0) AS sum
+            FROM ratings
+            WHERE movie_id = $1
+        ) AS aggregate
+        WHERE id = $1`
+
+	_, err := exec(m.dbWrite, m.tx).ExecContext(ctx, query, id)
+	return err
+}
+
 func (m *movieRepository) DeleteMovie(ctx context.Context, id int64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), config.AppConfig.CTX.Timeout)
 	defer cancel()
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+	"time"
+)
+
+// SessionsRevokedChannel is the Postgres channel that announces each revoked signed
+// session. The payload is the session id.
+const SessionsRevokedChannel = "sessions_revoked"
+
+// pingInterval is how long the listener may sit idle before it is pinged, so that a dead
+// connection is noticed and re-established.
+const pingInterval = 90 * time.Second
+
+// Subscriber handles the notifications sent on one Postgres channel.
+type Subscriber interface {
+	Channel() string
+	Notify(payload string)
+	// Resync is called once the connection has been re-established, since anything sent
+	// while it was down has been missed.
+	Resync(ctx context.Context)
+}
+
+// Notifications delivers what arrives on a single LISTEN connection to the subscriber of
+// each channel.
+type Notifications struct {
+	listener    *pq.Listener
+	subscribers map[string]Subscriber
+}
+
+// Run delivers notifications until ctx is cancelled, then closes the listener.
+func (n *Notifications) Run(ctx context.Context) {
+	defer n.listener.Close()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-n.listener.Notify:
+			if notification == nil {
+				for _, subscriber := range n.subscribers {
+					subscriber.Resync(ctx)
+				}
+				continue
+			}
+
+			if subscriber, ok := n.subscribers[notification.Channel]; ok {
+				subscriber.Notify(notification.Extra)
+			}
+		case <-ticker.C:
+			if err := n.listener.Ping(); err != nil {
+				slg.Logger.Error(err.Error())
+			}
+		}
+	}
+}
+
+// NewNotifications listens on the channel of every subscriber. It fails if the database
+// rejects any of them, rather than leaving the subscriber silently without updates.
+func NewNotifications(listener *pq.Listener, subscribers ...Subscriber) (*Notifications, error) {
+	n := &Notifications{
+		listener:    listener,
+		subscribers: make(map[string]Subscriber, len(subscribers)),
+	}
+
+	for _, subscriber := range subscribers {
+		if err := listener.Listen(subscriber.Channel()); err != nil {
+			listener.Close()
+			return nil, err
+		}
+
+		n.subscribers[subscriber.Channel()] = subscriber
+	}
+
+	return n, nil
+}
@@ -3,14 +3,31 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/lib/pq"
 	"github.com/saleh-ghazimoradi/Cinemaniac/config"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
 )
 
 type PermissionRepository interface {
-	GetAllForUser(userID int64) (*domain.Permissions, error)
+	GetAllForUser(ctx context.Context, userID int64) (*domain.Permissions, error)
 	AddForUser(userID int64, codes ...string) error
+	GetAll(ctx context.Context) ([]*domain.Permission, error)
+	GetPermission(ctx context.Context, code string) (*domain.Permission, error)
+	Insert(ctx context.Context, permission *domain.Permission) error
+	GrantForUser(ctx context.Context, userID int64, code string) (bool, error)
+	RevokeForUser(ctx context.Context, userID int64, code string) (bool, error)
+	GetRoles(ctx context.Context) ([]*domain.Role, error)
+	GetRole(ctx context.Context, name string) (*domain.Role, error)
+	InsertRole(ctx context.Context, role *domain.Role) error
+	GrantForRole(ctx context.Context, roleID int64, code string) (bool, error)
+	RevokeForRole(ctx context.Context, roleID int64, code string) (bool, error)
+	GetRolesForUser(ctx context.Context, userID int64) ([]string, error)
+	AssignRole(ctx context.Context, userID int64, name string) (bool, error)
+	UnassignRole(ctx context.Context, userID int64, name string) (bool, error)
+	InsertAudit(ctx context.Context, entry *domain.PermissionAuditEntry) error
+	GetAudit(ctx context.Context, userID int64, filters domain.Filters) ([]*domain.PermissionAuditEntry, domain.Metadata, error)
 	WithTx(ctx context.Context, tx *sql.Tx) PermissionRepository
 }
 
@@ -20,15 +37,22 @@ type permissionRepository struct {
 	tx      *sql.Tx
 }
 
-func (p *permissionRepository) GetAllForUser(userID int64) (*domain.Permissions, error) {
+// GetAllForUser returns the permissions granted to the user directly together with those
+// from the roles they are assigned.
+func (p *permissionRepository) GetAllForUser(ctx context.Context, userID int64) (*domain.Permissions, error) {
 	query := `
         SELECT permissions.code
         FROM permissions
         INNER JOIN users_permissions ON users_permissions.permission_id = permissions.id
-        INNER JOIN users ON users_permissions.user_id = users.id
-        WHERE users.id = $1`
+        WHERE users_permissions.user_id = $1
+        UNION
+        SELECT permissions.code
+        FROM permissions
+        INNER JOIN role_permissions ON role_permissions.permission_id = permissions.id
+        INNER JOIN users_roles ON users_roles.role_id = role_permissions.role_id
+        WHERE users_roles.user_id = $1`
 
-	ctx, cancel := context.WithTimeout(context.Background(), config.AppConfig.CTX.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
 	defer cancel()
 
 	rows, err := exec(p.dbRead, p.tx).QueryContext(ctx, query, userID)
@@ -54,7 +78,6 @@ func (p *permissionRepository) GetAllForUser(userID int64) (*domain.Permissions,
 	}
 
 	return &permissions, nil
-
 }
 
 func (p *permissionRepository) AddForUser(userID int64, codes ...string) error {
@@ -69,6 +92,440 @@ func (p *permissionRepository) AddForUser(userID int64, codes ...string) error {
 	return err
 }
 
+func (p *permissionRepository) GetAll(ctx context.Context) ([]*domain.Permission, error) {
+	query := `
+        SELECT id, code
+        FROM permissions
+        ORDER BY code ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(p.dbRead, p.tx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []*domain.Permission{}
+
+	for rows.Next() {
+		var permission domain.Permission
+		if err = rows.Scan(&permission.ID, &permission.Code); err != nil {
+			return nil, err
+		}
+
+		permissions = append(permissions, &permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func (p *permissionRepository) GetPermission(ctx context.Context, code string) (*domain.Permission, error) {
+	query := `
+        SELECT id, code
+        FROM permissions
+        WHERE code = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	permission := &domain.Permission{}
+
+	if err := exec(p.dbRead, p.tx).QueryRowContext(ctx, query, code).Scan(&permission.ID, &permission.Code); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return permission, nil
+}
+
+func (p *permissionRepository) Insert(ctx context.Context, permission *domain.Permission) error {
+	query := `
+        INSERT INTO permissions (code)
+        VALUES ($1)
+        RETURNING id`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	if err := exec(p.dbWrite, p.tx).QueryRowContext(ctx, query, permission.Code).Scan(&permission.ID); err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23505":
+			return ErrDuplicateCode
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GrantForUser gives the user the permission and reports whether they did not already
+// have it.
+func (p *permissionRepository) GrantForUser(ctx context.Context, userID int64, code string) (bool, error) {
+	query := `
+        INSERT INTO users_permissions (user_id, permission_id)
+        SELECT $1, permissions.id FROM permissions WHERE permissions.code = $2
+        ON CONFLICT DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(p.dbWrite, p.tx).ExecContext(ctx, query, userID, code)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			return false, ErrRecordNotFound
+		default:
+			return false, err
+		}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RevokeForUser takes the permission away from the user and reports whether they had it.
+func (p *permissionRepository) RevokeForUser(ctx context.Context, userID int64, code string) (bool, error) {
+	query := `
+        DELETE FROM users_permissions
+        USING permissions
+        WHERE users_permissions.permission_id = permissions.id
+        AND users_permissions.user_id = $1
+        AND permissions.code = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(p.dbWrite, p.tx).ExecContext(ctx, query, userID, code)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (p *permissionRepository) GetRoles(ctx context.Context) ([]*domain.Role, error) {
+	query := `
+        SELECT roles.id, roles.name, array_remove(array_agg(permissions.code ORDER BY permissions.code), NULL)
+        FROM roles
+        LEFT JOIN role_permissions ON role_permissions.role_id = roles.id
+        LEFT JOIN permissions ON permissions.id = role_permissions.permission_id
+        GROUP BY roles.id
+        ORDER BY roles.name ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(p.dbRead, p.tx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*domain.Role{}
+
+	for rows.Next() {
+		var role domain.Role
+		if err = rows.Scan(&role.ID, &role.Name, pq.Array(&role.Permissions)); err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, &role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (p *permissionRepository) GetRole(ctx context.Context, name string) (*domain.Role, error) {
+	query := `
+        SELECT roles.id, roles.name, array_remove(array_agg(permissions.code ORDER BY permissions.code), NULL)
+        FROM roles
+        LEFT JOIN role_permissions ON role_permissions.role_id = roles.id
+        LEFT JOIN permissions ON permissions.id = role_permissions.permission_id
+        WHERE roles.name = $1
+        GROUP BY roles.id`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	role := &domain.Role{}
+
+	if err := exec(p.dbRead, p.tx).QueryRowContext(ctx, query, name).Scan(&role.ID, &role.Name, pq.Array(&role.Permissions)); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return role, nil
+}
+
+// InsertRole creates the role without any permissions; they are added with GrantForRole.
+func (p *permissionRepository) InsertRole(ctx context.Context, role *domain.Role) error {
+	query := `
+        INSERT INTO roles (name)
+        VALUES ($1)
+        RETURNING id`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	if err := exec(p.dbWrite, p.tx).QueryRowContext(ctx, query, role.Name).Scan(&role.ID); err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23505":
+			return ErrDuplicateRole
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GrantForRole adds the permission to the role and reports whether it did not already
+// have it.
+func (p *permissionRepository) GrantForRole(ctx context.Context, roleID int64, code string) (bool, error) {
+	query := `
+        INSERT INTO role_permissions (role_id, permission_id)
+        SELECT $1, permissions.id FROM permissions WHERE permissions.code = $2
+        ON CONFLICT DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(p.dbWrite, p.tx).ExecContext(ctx, query, roleID, code)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			return false, ErrRecordNotFound
+		default:
+			return false, err
+		}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RevokeForRole removes the permission from the role and reports whether it had it.
+func (p *permissionRepository) RevokeForRole(ctx context.Context, roleID int64, code string) (bool, error) {
+	query := `
+        DELETE FROM role_permissions
+        USING permissions
+        WHERE role_permissions.permission_id = permissions.id
+        AND role_permissions.role_id = $1
+        AND permissions.code = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(p.dbWrite, p.tx).ExecContext(ctx, query, roleID, code)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (p *permissionRepository) GetRolesForUser(ctx context.Context, userID int64) ([]string, error) {
+	query := `
+        SELECT roles.name
+        FROM roles
+        INNER JOIN users_roles ON users_roles.role_id = roles.id
+        WHERE users_roles.user_id = $1
+        ORDER BY roles.name ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(p.dbRead, p.tx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []string{}
+
+	for rows.Next() {
+		var role string
+		if err = rows.Scan(&role); err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// AssignRole gives the user the named role and reports whether they did not already have
+// it. It fails with ErrRecordNotFound if the role or the user does not exist.
+func (p *permissionRepository) AssignRole(ctx context.Context, userID int64, name string) (bool, error) {
+	query := `
+        WITH role AS (
+            SELECT id FROM roles WHERE name = $2
+        ), assigned AS (
+            INSERT INTO users_roles (user_id, role_id)
+            SELECT $1, role.id FROM role
+            ON CONFLICT DO NOTHING
+            RETURNING role_id
+        )
+        SELECT EXISTS (SELECT 1 FROM role), EXISTS (SELECT 1 FROM assigned)`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	var found, assigned bool
+	if err := exec(p.dbWrite, p.tx).QueryRowContext(ctx, query, userID, name).Scan(&found, &assigned); err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			return false, ErrRecordNotFound
+		default:
+			return false, err
+		}
+	}
+
+	if !found {
+		return false, ErrRecordNotFound
+	}
+
+	return assigned, nil
+}
+
+// UnassignRole takes the named role away from the user and reports whether they had it.
+func (p *permissionRepository) UnassignRole(ctx context.Context, userID int64, name string) (bool, error) {
+	query := `
+        DELETE FROM users_roles
+        USING roles
+        WHERE users_roles.role_id = roles.id
+        AND users_roles.user_id = $1
+        AND roles.name = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(p.dbWrite, p.tx).ExecContext(ctx, query, userID, name)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (p *permissionRepository) InsertAudit(ctx context.Context, entry *domain.PermissionAuditEntry) error {
+	query := `
+        INSERT INTO permission_audit (actor_id, action, code, role, user_id)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`
+
+	args := []any{entry.ActorID, entry.Action, entry.Code, entry.Role, entry.UserID}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	return exec(p.dbWrite, p.tx).QueryRowContext(ctx, query, args...).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// GetAudit lists recorded permission changes. A zero userID lists changes for every user,
+// including the creation of new codes.
+func (p *permissionRepository) GetAudit(ctx context.Context, userID int64, filters domain.Filters) ([]*domain.PermissionAuditEntry, domain.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), id, created_at, actor_id, action, code, role, user_id
+        FROM permission_audit
+        WHERE (user_id = $1 OR $1 = 0)
+        ORDER BY %s %s, id ASC
+        LIMIT $2 OFFSET $3`, filters.SortColumn(), filters.SortDirection())
+
+	args := []any{userID, filters.Limit(), filters.Offset()}
+
+	rows, err := exec(p.dbRead, p.tx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, domain.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	entries := []*domain.PermissionAuditEntry{}
+
+	for rows.Next() {
+		var entry domain.PermissionAuditEntry
+		err = rows.Scan(
+			&totalRecords,
+			&entry.ID,
+			&entry.CreatedAt,
+			&entry.ActorID,
+			&entry.Action,
+			&entry.Code,
+			&entry.Role,
+			&entry.UserID,
+		)
+		if err != nil {
+			return nil, domain.Metadata{}, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.CalculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return entries, metadata, nil
+}
+
 func (p *permissionRepository) WithTx(ctx context.Context, tx *sql.Tx) PermissionRepository {
 	return &permissionRepository{
 		dbWrite: p.dbWrite,
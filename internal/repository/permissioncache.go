@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PermissionsChannel is the Postgres channel that the permission triggers notify whenever
+// a grant or role assignment changes. The payload is the affected user's id, or empty
+// when a role changed and any user may be affected.
+const PermissionsChannel = "permissions_changed"
+
+type cachedPermissions struct {
+	permissions domain.Permissions
+	expiresAt   time.Time
+}
+
+// PermissionCache keeps each user's permissions in memory for up to ttl. Only
+// GetAllForUser is cached; everything else, including any use inside a transaction, goes
+// straight to the wrapped repository.
+type PermissionCache struct {
+	PermissionRepository
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[int64]cachedPermissions
+	// generation is bumped on every invalidation, so that a load which started before one
+	// does not store what it read.
+	generation uint64
+}
+
+func (c *PermissionCache) GetAllForUser(ctx context.Context, userID int64) (*domain.Permissions, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[userID]
+	generation := c.generation
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		permissions := slices.Clone(entry.permissions)
+		return &permissions, nil
+	}
+
+	permissions, err := c.PermissionRepository.GetAllForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.entries[userID] = cachedPermissions{
+			permissions: slices.Clone(*permissions),
+			expiresAt:   time.Now().Add(c.ttl),
+		}
+	}
+	c.mu.Unlock()
+
+	return permissions, nil
+}
+
+func (c *PermissionCache) Invalidate(userID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, userID)
+	c.generation++
+}
+
+func (c *PermissionCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	c.generation++
+}
+
+func (c *PermissionCache) Channel() string {
+	return PermissionsChannel
+}
+
+func (c *PermissionCache) Notify(payload string) {
+	if payload == "" {
+		c.InvalidateAll()
+		return
+	}
+
+	userID, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		slg.Logger.Error("invalid permissions notification", "payload", payload)
+		c.InvalidateAll()
+		return
+	}
+
+	c.Invalidate(userID)
+}
+
+// Resync drops the whole cache, since invalidations may have been missed.
+func (c *PermissionCache) Resync(ctx context.Context) {
+	c.InvalidateAll()
+}
+
+func NewPermissionCache(repo PermissionRepository, ttl time.Duration) *PermissionCache {
+	return &PermissionCache{
+		PermissionRepository: repo,
+		ttl:                  ttl,
+		entries:              make(map[int64]cachedPermissions),
+	}
+}
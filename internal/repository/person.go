@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+)
+
+type PersonRepository interface {
+	CreatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error)
+	GetPersonById(ctx context.Context, id int64) (*domain.Person, error)
+	GetPeople(ctx context.Context, name string, filters domain.Filters) ([]*domain.Person, domain.Metadata, error)
+	UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error)
+	DeletePerson(ctx context.Context, id int64) error
+	CreateCredit(ctx context.Context, credit *domain.Credit) (*domain.Credit, error)
+	DeleteCredit(ctx context.Context, movieID, creditID int64) error
+	GetCreditsForMovie(ctx context.Context, movieID int64) ([]*domain.Credit, error)
+	GetFilmography(ctx context.Context, personID int64) ([]*domain.Credit, error)
+	WithTx(ctx context.Context, tx *sql.Tx) PersonRepository
+}
+
+type personRepository struct {
+	dbWrite *sql.DB
+	dbRead  *sql.DB
+	tx      *sql.Tx
+}
+
+func (p *personRepository) CreatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	query := `
+        INSERT INTO people (name, biography)
+        VALUES ($1, $2)
+        RETURNING id, created_at, version`
+
+	args := []any{person.Name, person.Biography}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(p.dbWrite, p.tx).QueryRowContext(ctx, query, args...).Scan(&person.ID, &person.CreatedAt, &person.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return person, nil
+}
+
+func (p *personRepository) GetPersonById(ctx context.Context, id int64) (*domain.Person, error) {
+	query := `
+        SELECT id, created_at, name, biography, version
+        FROM people
+        WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	person := &domain.Person{}
+
+	if err := exec(p.dbRead, p.tx).QueryRowContext(ctx, query, id).Scan(
+		&person.ID,
+		&person.CreatedAt,
+		&person.Name,
+		&person.Biography,
+		&person.Version,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return person, nil
+}
+
+func (p *personRepository) GetPeople(ctx context.Context, name string, filters domain.Filters) ([]*domain.Person, domain.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), id, created_at, name, biography, version
+        FROM people
+        WHERE (to_tsvector('simple', name) @@ plainto_tsquery('simple', $1) OR $1 = '')
+        ORDER BY %s %s, id ASC
+        LIMIT $2 OFFSET $3`, filters.SortColumn(), filters.SortDirection())
+
+	args := []any{name, filters.Limit(), filters.Offset()}
+
+	rows, err := exec(p.dbRead, p.tx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, domain.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	people := []*domain.Person{}
+
+	for rows.Next() {
+		var person domain.Person
+		err = rows.Scan(
+			&totalRecords,
+			&person.ID,
+			&person.CreatedAt,
+			&person.Name,
+			&person.Biography,
+			&person.Version,
+		)
+		if err != nil {
+			return nil, domain.Metadata{}, err
+		}
+
+		people = append(people, &person)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.CalculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return people, metadata, nil
+}
+
+func (p *personRepository) UpdatePerson(ctx context.Context, person *domain.Person) (*domain.Person, error) {
+	query := `
+        UPDATE people
+        SET name = $1, biography = $2, version = version + 1
+        WHERE id = $3 AND version = $4
+        RETURNING version`
+
+	args := []any{
+		person.Name,
+		person.Biography,
+		person.ID,
+		person.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	if err := exec(p.dbWrite, p.tx).QueryRowContext(ctx, query, args...).Scan(&person.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, err
+		}
+	}
+
+	return person, nil
+}
+
+func (p *personRepository) DeletePerson(ctx context.Context, id int64) error {
+	query := `DELETE FROM people WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(p.dbWrite, p.tx).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (p *personRepository) CreateCredit(ctx context.Context, credit *domain.Credit) (*domain.Credit, error) {
+	query := `
+        INSERT INTO credits (movie_id, person_id, role, character_name, billing_order)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id`
+
+	args := []any{credit.MovieID, credit.PersonID, credit.Role, credit.CharacterName, credit.BillingOrder}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(p.dbWrite, p.tx).QueryRowContext(ctx, query, args...).Scan(&credit.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return credit, nil
+}
+
+func (p *personRepository) DeleteCredit(ctx context.Context, movieID, creditID int64) error {
+	query := `DELETE FROM credits WHERE id = $1 AND movie_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(p.dbWrite, p.tx).ExecContext(ctx, query, creditID, movieID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (p *personRepository) GetCreditsForMovie(ctx context.Context, movieID int64) ([]*domain.Credit, error) {
+	query := `
+        SELECT credits.id, credits.movie_id, credits.person_id, people.name, credits.role, credits.character_name, credits.billing_order
+        FROM credits
+        INNER JOIN people ON people.id = credits.person_id
+        WHERE credits.movie_id = $1
+        ORDER BY credits.billing_order ASC, credits.id ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(p.dbRead, p.tx).QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []*domain.Credit{}
+
+	for rows.Next() {
+		var credit domain.Credit
+		err = rows.Scan(
+			&credit.ID,
+			&credit.MovieID,
+			&credit.PersonID,
+			&credit.PersonName,
+			&credit.Role,
+			&credit.CharacterName,
+			&credit.BillingOrder,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		credits = append(credits, &credit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
+
+func (p *personRepository) GetFilmography(ctx context.Context, personID int64) ([]*domain.Credit, error) {
+	query := `
+        SELECT credits.id, credits.movie_id, movies.title, movies.year, credits.person_id, credits.role, credits.character_name, credits.billing_order
+        FROM credits
+        INNER JOIN movies ON movies.id = credits.movie_id
+        WHERE credits.person_id = $1
+        ORDER BY movies.year DESC, movies.id ASC, credits.billing_order ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(p.dbRead, p.tx).QueryContext(ctx, query, personID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []*domain.Credit{}
+
+	for rows.Next() {
+		var credit domain.Credit
+		err = rows.Scan(
+			&credit.ID,
+			&credit.MovieID,
+			&credit.MovieTitle,
+			&credit.MovieYear,
+			&credit.PersonID,
+			&credit.Role,
+			&credit.CharacterName,
+			&credit.BillingOrder,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		credits = append(credits, &credit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
+
+func (p *personRepository) WithTx(ctx context.Context, tx *sql.Tx) PersonRepository {
+	return &personRepository{
+		dbWrite: p.dbWrite,
+		dbRead:  p.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewPersonRepository(dbWrite, dbRead *sql.DB) PersonRepository {
+	return &personRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+)
+
+type RatingRepository interface {
+	GetRating(ctx context.Context, userID, movieID int64) (*domain.Rating, error)
+	UpsertRating(ctx context.Context, rating *domain.Rating) error
+	DeleteRating(ctx context.Context, userID, movieID int64) error
+	WithTx(ctx context.Context, tx *sql.Tx) RatingRepository
+}
+
+type ratingRepository struct {
+	dbWrite *sql.DB
+	dbRead  *sql.DB
+	tx      *sql.Tx
+}
+
+func (r *ratingRepository) GetRating(ctx context.Context, userID, movieID int64) (*domain.Rating, error) {
+	query := `
+        SELECT user_id, movie_id, score, created_at, updated_at
+        FROM ratings
+        WHERE user_id = $1 AND movie_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rating := &domain.Rating{}
+
+	if err := exec(r.dbRead, r.tx).QueryRowContext(ctx, query, userID, movieID).Scan(
+		&rating.UserID,
+		&rating.MovieID,
+		&rating.Score,
+		&rating.CreatedAt,
+		&rating.UpdatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return rating, nil
+}
+
+// UpsertRating inserts the rating or replaces the user's existing score for the movie.
+func (r *ratingRepository) UpsertRating(ctx context.Context, rating *domain.Rating) error {
+	query := `
+        INSERT INTO ratings (user_id, movie_id, score)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id, movie_id) DO UPDATE SET score = EXCLUDED.score, updated_at = NOW()
+        RETURNING created_at, updated_at`
+
+	args := []any{rating.UserID, rating.MovieID, rating.Score}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(r.dbWrite, r.tx).QueryRowContext(ctx, query, args...).Scan(&rating.CreatedAt, &rating.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ratingRepository) DeleteRating(ctx context.Context, userID, movieID int64) error {
+	query := `
+        DELETE FROM ratings
+        WHERE user_id = $1 AND movie_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(r.dbWrite, r.tx).ExecContext(ctx, query, userID, movieID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *ratingRepository) WithTx(ctx context.Context, tx *sql.Tx) RatingRepository {
+	return &ratingRepository{
+		dbWrite: r.dbWrite,
+		dbRead:  r.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewRatingRepository(dbWrite, dbRead *sql.DB) RatingRepository {
+	return &ratingRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+)
+
+type ReviewRepository interface {
+	CreateReview(ctx context.Context, review *domain.Review) (*domain.Review, error)
+	GetReviewById(ctx context.Context, id int64) (*domain.Review, error)
+	GetReviews(ctx context.Context, movieID int64, status string, filters domain.Filters) ([]*domain.Review, domain.Metadata, error)
+	UpdateReview(ctx context.Context, review *domain.Review) (*domain.Review, error)
+	DeleteReview(ctx context.Context, id int64) error
+	WithTx(ctx context.Context, tx *sql.Tx) ReviewRepository
+}
+
+type reviewRepository struct {
+	dbWrite *sql.DB
+	dbRead  *sql.DB
+	tx      *sql.Tx
+}
+
+func (r *reviewRepository) CreateReview(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	query := `
+        INSERT INTO reviews (movie_id, user_id, title, body, status)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at, updated_at, version`
+
+	args := []any{review.MovieID, review.UserID, review.Title, review.Body, review.Status}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(r.dbWrite, r.tx).QueryRowContext(ctx, query, args...).Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt, &review.Version)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Code == "23505":
+			return nil, ErrDuplicateReview
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return review, nil
+}
+
+func (r *reviewRepository) GetReviewById(ctx context.Context, id int64) (*domain.Review, error) {
+	query := `
+        SELECT reviews.id, reviews.created_at, reviews.updated_at, reviews.movie_id, reviews.user_id, users.name,
+            reviews.title, reviews.body, reviews.status, reviews.moderation_reason, reviews.moderated_by, reviews.moderated_at, reviews.version
+        FROM reviews
+        INNER JOIN users ON users.id = reviews.user_id
+        WHERE reviews.id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	review := &domain.Review{}
+
+	if err := exec(r.dbRead, r.tx).QueryRowContext(ctx, query, id).Scan(
+		&review.ID,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+		&review.MovieID,
+		&review.UserID,
+		&review.UserName,
+		&review.Title,
+		&review.Body,
+		&review.Status,
+		&review.ModerationReason,
+		&review.ModeratedBy,
+		&review.ModeratedAt,
+		&review.Version,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return review, nil
+}
+
+// GetReviews lists reviews in the given status. A zero movieID lists reviews across all
+// movies, which is what the moderation queue uses.
+func (r *reviewRepository) GetReviews(ctx context.Context, movieID int64, status string, filters domain.Filters) ([]*domain.Review, domain.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+        SELECT count(*) OVER(), reviews.id, reviews.created_at, reviews.updated_at, reviews.movie_id, reviews.user_id, users.name,
+            reviews.title, reviews.body, reviews.status, reviews.moderation_reason, reviews.moderated_by, reviews.moderated_at, reviews.version
+        FROM reviews
+        INNER JOIN users ON users.id = reviews.user_id
+        WHERE (reviews.movie_id = $1 OR $1 = 0)
+        AND reviews.status = $2
+        ORDER BY reviews.%s %s, reviews.id ASC
+        LIMIT $3 OFFSET $4`, filters.SortColumn(), filters.SortDirection())
+
+	args := []any{movieID, status, filters.Limit(), filters.Offset()}
+
+	rows, err := exec(r.dbRead, r.tx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, domain.Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	reviews := []*domain.Review{}
+
+	for rows.Next() {
+		var review domain.Review
+		err = rows.Scan(
+			&totalRecords,
+			&review.ID,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+			&review.MovieID,
+			&review.UserID,
+			&review.UserName,
+			&review.Title,
+			&review.Body,
+			&review.Status,
+			&review.ModerationReason,
+			&review.ModeratedBy,
+			&review.ModeratedAt,
+			&review.Version,
+		)
+		if err != nil {
+			return nil, domain.Metadata{}, err
+		}
+
+		reviews = append(reviews, &review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, domain.Metadata{}, err
+	}
+
+	metadata := domain.CalculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return reviews, metadata, nil
+}
+
+func (r *reviewRepository) UpdateReview(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	query := `
+        UPDATE reviews
+        SET title = $1, body = $2, status = $3, moderation_reason = $4, moderated_by = $5, moderated_at = $6,
+            updated_at = NOW(), version = version + 1
+        WHERE id = $7 AND version = $8
+        RETURNING updated_at, version`
+
+	args := []any{
+		review.Title,
+		review.Body,
+		review.Status,
+		review.ModerationReason,
+		review.ModeratedBy,
+		review.ModeratedAt,
+		review.ID,
+		review.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	if err := exec(r.dbWrite, r.tx).QueryRowContext(ctx, query, args...).Scan(&review.UpdatedAt, &review.Version); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, err
+		}
+	}
+
+	return review, nil
+}
+
+func (r *reviewRepository) DeleteReview(ctx context.Context, id int64) error {
+	query := `DELETE FROM reviews WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(r.dbWrite, r.tx).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *reviewRepository) WithTx(ctx context.Context, tx *sql.Tx) ReviewRepository {
+	return &reviewRepository{
+		dbWrite: r.dbWrite,
+		dbRead:  r.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewReviewRepository(dbWrite, dbRead *sql.DB) ReviewRepository {
+	return &reviewRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -2,14 +2,32 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"errors"
+	"github.com/lib/pq"
 	"github.com/saleh-ghazimoradi/Cinemaniac/config"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"time"
 )
 
 type TokenRepository interface {
 	Insert(ctx context.Context, token *domain.Token) error
 	DeleteAllForUser(ctx context.Context, scope string, userId int64) error
+	DeleteFamily(ctx context.Context, family string) error
+	Consume(ctx context.Context, scope, tokenPlaintext string) error
+	DeleteFamilyScope(ctx context.Context, family, scope string) error
+	GetForPlaintext(ctx context.Context, scope, tokenPlaintext string) (*domain.Token, error)
+	MarkRotated(ctx context.Context, token *domain.Token) error
+	GetSessionsForUser(ctx context.Context, userID int64, currentFamily string) ([]*domain.Session, error)
+	GetFamilyForToken(ctx context.Context, scope, tokenPlaintext string) (string, error)
+	GetFamiliesForUser(ctx context.Context, userID int64) ([]string, error)
+	DeleteOtherFamilies(ctx context.Context, userID int64, keepFamily string) ([]string, error)
+	DeleteSession(ctx context.Context, userID, id int64) (string, error)
+	TouchLastUsed(ctx context.Context, tokenPlaintext string) error
+	RevokeSessions(ctx context.Context, families []string, expiresAt time.Time) error
+	GetRevokedSessions(ctx context.Context) (map[string]time.Time, error)
+	DeleteExpiredRevokedSessions(ctx context.Context) error
 	WithTx(ctx context.Context, tx *sql.Tx) TokenRepository
 }
 
@@ -21,10 +39,10 @@ type tokenRepository struct {
 
 func (t *tokenRepository) Insert(ctx context.Context, token *domain.Token) error {
 	query := `
-        INSERT INTO tokens (hash, user_id, expiry, scope) 
-        VALUES ($1, $2, $3, $4)`
+        INSERT INTO tokens (hash, user_id, expiry, scope, ip, user_agent, family, payload) 
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
-	args := []any{token.Hash, token.UserID, token.Expiry, token.Scope}
+	args := []any{token.Hash, token.UserID, token.Expiry, token.Scope, token.IP, token.UserAgent, token.Family, token.Payload}
 
 	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
 	defer cancel()
@@ -46,6 +64,367 @@ func (t *tokenRepository) DeleteAllForUser(ctx context.Context, scope string, us
 	return err
 }
 
+// Consume deletes a single-use token and fails with ErrRecordNotFound if it has already
+// been used, so concurrent attempts cannot both succeed.
+func (t *tokenRepository) Consume(ctx context.Context, scope, tokenPlaintext string) error {
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	query := `
+        DELETE FROM tokens
+        WHERE hash = $1 AND scope = $2 AND expiry > $3`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query, tokenHash[:], scope, time.Now())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *tokenRepository) DeleteFamily(ctx context.Context, family string) error {
+	query := `
+        DELETE FROM tokens
+        WHERE family = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query, family)
+	return err
+}
+
+func (t *tokenRepository) DeleteFamilyScope(ctx context.Context, family, scope string) error {
+	query := `
+        DELETE FROM tokens
+        WHERE family = $1 AND scope = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query, family, scope)
+	return err
+}
+
+// GetForPlaintext locks the token row so that concurrent refreshes of the same token
+// are serialised by the surrounding transaction.
+func (t *tokenRepository) GetForPlaintext(ctx context.Context, scope, tokenPlaintext string) (*domain.Token, error) {
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	query := `
+        SELECT hash, user_id, expiry, scope, family, rotated, payload
+        FROM tokens
+        WHERE hash = $1 AND scope = $2
+        FOR UPDATE`
+
+	token := &domain.Token{
+		Plaintext: tokenPlaintext,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(t.dbRead, t.tx).QueryRowContext(ctx, query, tokenHash[:], scope).Scan(
+		&token.Hash,
+		&token.UserID,
+		&token.Expiry,
+		&token.Scope,
+		&token.Family,
+		&token.Rotated,
+		&token.Payload,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return token, nil
+}
+
+func (t *tokenRepository) MarkRotated(ctx context.Context, token *domain.Token) error {
+	query := `
+        UPDATE tokens
+        SET rotated = true
+        WHERE hash = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query, token.Hash)
+	if err != nil {
+		return err
+	}
+
+	token.Rotated = true
+
+	return nil
+}
+
+// GetSessionsForUser lists one entry per token family. The family's current refresh token
+// stands for the session since, unlike authentication tokens, it exists in every auth mode.
+func (t *tokenRepository) GetSessionsForUser(ctx context.Context, userID int64, currentFamily string) ([]*domain.Session, error) {
+	query := `
+        SELECT id, created_at, last_used_at, expiry, ip, user_agent, family = $3
+        FROM tokens
+        WHERE user_id = $1 AND scope = $2 AND NOT rotated AND expiry > $4
+        ORDER BY last_used_at DESC, id DESC`
+
+	args := []any{userID, domain.ScopeRefresh, currentFamily, time.Now()}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(t.dbRead, t.tx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*domain.Session{}
+
+	for rows.Next() {
+		var session domain.Session
+		err = rows.Scan(
+			&session.ID,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.Expiry,
+			&session.IP,
+			&session.UserAgent,
+			&session.Current,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (t *tokenRepository) GetFamilyForToken(ctx context.Context, scope, tokenPlaintext string) (string, error) {
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	query := `
+        SELECT family
+        FROM tokens
+        WHERE hash = $1 AND scope = $2`
+
+	var family string
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(t.dbRead, t.tx).QueryRowContext(ctx, query, tokenHash[:], scope).Scan(&family)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrRecordNotFound
+		default:
+			return "", err
+		}
+	}
+
+	return family, nil
+}
+
+func (t *tokenRepository) GetFamiliesForUser(ctx context.Context, userID int64) ([]string, error) {
+	query := `
+        SELECT DISTINCT family
+        FROM tokens
+        WHERE user_id = $1 AND scope IN ($2, $3)`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(t.dbRead, t.tx).QueryContext(ctx, query, userID, domain.ScopeAuthentication, domain.ScopeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	families := []string{}
+
+	for rows.Next() {
+		var family string
+		if err = rows.Scan(&family); err != nil {
+			return nil, err
+		}
+
+		families = append(families, family)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return families, nil
+}
+
+// DeleteOtherFamilies signs the user out of every session except keepFamily and returns
+// the families that were removed.
+func (t *tokenRepository) DeleteOtherFamilies(ctx context.Context, userID int64, keepFamily string) ([]string, error) {
+	query := `
+        DELETE FROM tokens
+        WHERE user_id = $1 AND scope IN ($2, $3) AND family <> $4
+        RETURNING family`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(t.dbWrite, t.tx).QueryContext(ctx, query, userID, domain.ScopeAuthentication, domain.ScopeRefresh, keepFamily)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := map[string]bool{}
+	families := []string{}
+
+	for rows.Next() {
+		var family string
+		if err = rows.Scan(&family); err != nil {
+			return nil, err
+		}
+
+		if !seen[family] {
+			seen[family] = true
+			families = append(families, family)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return families, nil
+}
+
+// DeleteSession removes the token family the session belongs to and returns the family.
+func (t *tokenRepository) DeleteSession(ctx context.Context, userID, id int64) (string, error) {
+	query := `
+        DELETE FROM tokens
+        WHERE user_id = $2
+        AND family = (SELECT family FROM tokens WHERE id = $1 AND user_id = $2)
+        RETURNING family`
+
+	var family string
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(t.dbWrite, t.tx).QueryRowContext(ctx, query, id, userID).Scan(&family)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrRecordNotFound
+		default:
+			return "", err
+		}
+	}
+
+	return family, nil
+}
+
+func (t *tokenRepository) TouchLastUsed(ctx context.Context, tokenPlaintext string) error {
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	query := `
+        UPDATE tokens
+        SET last_used_at = NOW()
+        WHERE family = (SELECT family FROM tokens WHERE hash = $1)`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query, tokenHash[:])
+	return err
+}
+
+// RevokeSessions records that signed tokens of the given families must be rejected until
+// expiresAt. Each recorded family is announced on SessionsRevokedChannel once the
+// transaction commits.
+func (t *tokenRepository) RevokeSessions(ctx context.Context, families []string, expiresAt time.Time) error {
+	query := `
+        INSERT INTO revoked_sessions (session_id, expires_at)
+        SELECT unnest($1::text[]), $2
+        ON CONFLICT (session_id) DO UPDATE SET expires_at = GREATEST(revoked_sessions.expires_at, EXCLUDED.expires_at)`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query, pq.Array(families), expiresAt)
+	return err
+}
+
+func (t *tokenRepository) GetRevokedSessions(ctx context.Context) (map[string]time.Time, error) {
+	query := `
+        SELECT session_id, expires_at
+        FROM revoked_sessions
+        WHERE expires_at > NOW()`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	rows, err := exec(t.dbRead, t.tx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make(map[string]time.Time)
+
+	for rows.Next() {
+		var (
+			sessionID string
+			expiresAt time.Time
+		)
+		if err = rows.Scan(&sessionID, &expiresAt); err != nil {
+			return nil, err
+		}
+
+		sessions[sessionID] = expiresAt
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (t *tokenRepository) DeleteExpiredRevokedSessions(ctx context.Context) error {
+	query := `
+        DELETE FROM revoked_sessions
+        WHERE expires_at <= NOW()`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query)
+	return err
+}
+
 func (t *tokenRepository) WithTx(ctx context.Context, tx *sql.Tx) TokenRepository {
 	return &tokenRepository{
 		dbWrite: t.dbWrite,
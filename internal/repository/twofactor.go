@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+)
+
+type TwoFactorRepository interface {
+	GetTwoFactor(ctx context.Context, userID int64) (*domain.TwoFactor, error)
+	UpsertPending(ctx context.Context, twoFactor *domain.TwoFactor) error
+	Confirm(ctx context.Context, userID int64) error
+	UseStep(ctx context.Context, userID, step int64) error
+	DeleteTwoFactor(ctx context.Context, userID int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes [][]byte) error
+	UseRecoveryCode(ctx context.Context, userID int64, hash []byte) error
+	CountRecoveryCodes(ctx context.Context, userID int64) (int, error)
+	WithTx(ctx context.Context, tx *sql.Tx) TwoFactorRepository
+}
+
+type twoFactorRepository struct {
+	dbWrite *sql.DB
+	dbRead  *sql.DB
+	tx      *sql.Tx
+}
+
+func (t *twoFactorRepository) GetTwoFactor(ctx context.Context, userID int64) (*domain.TwoFactor, error) {
+	query := `
+        SELECT user_id, created_at, secret, confirmed, last_used_step
+        FROM user_totp
+        WHERE user_id = $1`
+
+	twoFactor := &domain.TwoFactor{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(t.dbRead, t.tx).QueryRowContext(ctx, query, userID).Scan(
+		&twoFactor.UserID,
+		&twoFactor.CreatedAt,
+		&twoFactor.Secret,
+		&twoFactor.Confirmed,
+		&twoFactor.LastUsedStep,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return twoFactor, nil
+}
+
+// UpsertPending stores a new unconfirmed secret, replacing an earlier unconfirmed one. It
+// returns ErrEditConflict if 2FA is already enabled for the user.
+func (t *twoFactorRepository) UpsertPending(ctx context.Context, twoFactor *domain.TwoFactor) error {
+	query := `
+        INSERT INTO user_totp (user_id, secret)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id) DO UPDATE
+        SET secret = EXCLUDED.secret, created_at = NOW(), last_used_step = 0
+        WHERE NOT user_totp.confirmed
+        RETURNING created_at`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(t.dbWrite, t.tx).QueryRowContext(ctx, query, twoFactor.UserID, twoFactor.Secret).Scan(&twoFactor.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *twoFactorRepository) Confirm(ctx context.Context, userID int64) error {
+	query := `UPDATE user_totp SET confirmed = true WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	_, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query, userID)
+	return err
+}
+
+// UseStep records that the code of a time step has been accepted. A step can only be used
+// once, so a replayed code fails with ErrEditConflict.
+func (t *twoFactorRepository) UseStep(ctx context.Context, userID, step int64) error {
+	query := `
+        UPDATE user_totp
+        SET last_used_step = $2
+        WHERE user_id = $1 AND last_used_step < $2`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrEditConflict
+	}
+
+	return nil
+}
+
+func (t *twoFactorRepository) DeleteTwoFactor(ctx context.Context, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	conn := exec(t.dbWrite, t.tx)
+
+	if _, err := conn.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	_, err := conn.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	return err
+}
+
+func (t *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes [][]byte) error {
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	conn := exec(t.dbWrite, t.tx)
+
+	if _, err := conn.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO totp_recovery_codes (user_id, hash)
+        SELECT $1, unnest($2::bytea[])`
+
+	_, err := conn.ExecContext(ctx, query, userID, pq.Array(hashes))
+	return err
+}
+
+func (t *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID int64, hash []byte) error {
+	query := `
+        UPDATE totp_recovery_codes
+        SET used_at = NOW()
+        WHERE user_id = $1 AND hash = $2 AND used_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	result, err := exec(t.dbWrite, t.tx).ExecContext(ctx, query, userID, hash)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (t *twoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	query := `
+        SELECT count(*)
+        FROM totp_recovery_codes
+        WHERE user_id = $1 AND used_at IS NULL`
+
+	var count int
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(t.dbRead, t.tx).QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (t *twoFactorRepository) WithTx(ctx context.Context, tx *sql.Tx) TwoFactorRepository {
+	return &twoFactorRepository{
+		dbWrite: t.dbWrite,
+		dbRead:  t.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewTwoFactorRepository(dbWrite, dbRead *sql.DB) TwoFactorRepository {
+	return &twoFactorRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
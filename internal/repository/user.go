@@ -12,6 +12,7 @@ import (
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *domain.User) error
+	GetUserById(ctx context.Context, id int64) (*domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	UpdateUser(ctx context.Context, user *domain.User) error
 	GetForToken(ctx context.Context, tokenScope, tokenPlaintext string) (*domain.User, error)
@@ -48,6 +49,39 @@ func (u *userRepository) CreateUser(ctx context.Context, user *domain.User) erro
 	return nil
 }
 
+func (u *userRepository) GetUserById(ctx context.Context, id int64) (*domain.User, error) {
+	query := `
+        SELECT id, created_at, name, email, password_hash, activated, version
+        FROM users
+        WHERE id = $1`
+
+	user := &domain.User{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AppConfig.CTX.Timeout)
+	defer cancel()
+
+	err := exec(u.dbRead, u.tx).QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.CreatedAt,
+		&user.Name,
+		&user.Email,
+		&user.Password.Hash,
+		&user.Activated,
+		&user.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
 func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
         SELECT id, created_at, name, email, password_hash, activated, version
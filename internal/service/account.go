@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
+	"strings"
+	"time"
+)
+
+// GetProfile reloads the user rather than trusting the copy cached on the request, which
+// may predate a change made by another session.
+func (u *userService) GetProfile(ctx context.Context, user *domain.User) (*domain.User, error) {
+	return u.userRepository.GetUserById(ctx, user.ID)
+}
+
+func (u *userService) UpdateProfile(ctx context.Context, user *domain.User, input *dto.UpdateProfile) (*domain.User, error) {
+	account, err := u.userRepository.GetUserById(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		account.Name = *input.Name
+	}
+
+	v := validator.New()
+
+	if domain.ValidateUser(v, account); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	err = u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return u.userRepository.WithTx(ctx, tx).UpdateUser(ctx, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// ChangePassword sets a new password once the current one has been confirmed, and signs
+// the user out everywhere except the session making the change.
+func (u *userService) ChangePassword(ctx context.Context, user *domain.User, currentToken string, input *dto.ChangePassword) error {
+	v := validator.New()
+
+	v.Check(input.CurrentPassword != "", "current_password", "must be provided")
+	domain.ValidatePasswordPlaintext(v, input.Password)
+	if err := v.GetValidationError(); err != nil {
+		return err
+	}
+
+	account, err := u.userRepository.GetUserById(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	match, err := account.Password.Matches(input.CurrentPassword)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		return ErrInvalidCredentials
+	}
+
+	if err = account.Password.Set(input.Password); err != nil {
+		return err
+	}
+
+	currentFamily, err := u.sessionFamily(ctx, currentToken)
+	if err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		return err
+	}
+
+	return u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		if err := u.userRepository.WithTx(ctx, tx).UpdateUser(ctx, account); err != nil {
+			return err
+		}
+
+		if err := txTokenRepo.DeleteAllForUser(ctx, domain.ScopePasswordReset, account.ID); err != nil {
+			return err
+		}
+
+		revoked, err := txTokenRepo.DeleteOtherFamilies(ctx, account.ID, currentFamily)
+		if err != nil {
+			return err
+		}
+
+		return u.revokeSignedSessions(ctx, txTokenRepo, revoked...)
+	})
+}
+
+// RequestEmailChange emails a confirmation token to the new address. The account keeps its
+// current address until the token is confirmed.
+func (u *userService) RequestEmailChange(ctx context.Context, user *domain.User, input *dto.ChangeEmail) error {
+	v := validator.New()
+
+	domain.ValidateEmail(v, input.Email)
+	v.Check(!strings.EqualFold(input.Email, user.Email), "email", "must be different from the current email address")
+	v.Check(input.Password != "", "password", "must be provided")
+	if err := v.GetValidationError(); err != nil {
+		return err
+	}
+
+	account, err := u.userRepository.GetUserById(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	match, err := account.Password.Matches(input.Password)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		return ErrInvalidCredentials
+	}
+
+	_, err = u.userRepository.GetUserByEmail(ctx, input.Email)
+	switch {
+	case err == nil:
+		return repository.ErrDuplicateEmail
+	case !errors.Is(err, repository.ErrRecordNotFound):
+		return err
+	}
+
+	token := utils.GenerateToken(account.ID, config.AppConfig.Token.EmailChangeTTL, domain.ScopeEmailChange)
+	token.Payload = input.Email
+
+	err = u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		if err := txTokenRepo.DeleteAllForUser(ctx, domain.ScopeEmailChange, account.ID); err != nil {
+			return err
+		}
+
+		return txTokenRepo.Insert(ctx, token)
+	})
+	if err != nil {
+		return err
+	}
+
+	background(func() {
+		data := map[string]any{
+			"emailChangeToken": token.Plaintext,
+			"expiresIn":        config.AppConfig.Token.EmailChangeTTL.String(),
+		}
+
+		err := u.notification.Send(input.Email, "token_email_change.tmpl", data)
+		if err != nil {
+			slg.Logger.Error(err.Error())
+		}
+	})
+
+	return nil
+}
+
+// ConfirmEmailChange switches the account to the new address. Every session is signed out
+// and the previous address is told, so that a hijacked session cannot quietly take the
+// account over.
+func (u *userService) ConfirmEmailChange(ctx context.Context, input *dto.ConfirmEmailChange) (*domain.User, error) {
+	v := validator.New()
+
+	if domain.ValidateTokenPlaintext(v, input.TokenPlaintext); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var (
+		account       *domain.User
+		previousEmail string
+	)
+
+	err := u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txUserRepo := u.userRepository.WithTx(ctx, tx)
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		token, err := txTokenRepo.GetForPlaintext(ctx, domain.ScopeEmailChange, input.TokenPlaintext)
+		if err != nil {
+			return err
+		}
+
+		if token.Expiry.Before(time.Now()) {
+			return repository.ErrRecordNotFound
+		}
+
+		account, err = txUserRepo.GetUserById(ctx, token.UserID)
+		if err != nil {
+			return err
+		}
+
+		previousEmail = account.Email
+		account.Email = token.Payload
+
+		if err = txUserRepo.UpdateUser(ctx, account); err != nil {
+			return err
+		}
+
+		if err = txTokenRepo.DeleteAllForUser(ctx, domain.ScopeEmailChange, account.ID); err != nil {
+			return err
+		}
+
+		if err = txTokenRepo.DeleteAllForUser(ctx, domain.ScopePasswordReset, account.ID); err != nil {
+			return err
+		}
+
+		return u.revokeAllSessions(ctx, txTokenRepo, account.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	background(func() {
+		data := map[string]any{
+			"newEmail": account.Email,
+		}
+
+		err := u.notification.Send(previousEmail, "email_changed.tmpl", data)
+		if err != nil {
+			slg.Logger.Error(err.Error())
+		}
+	})
+
+	return account, nil
+}
+
+// ExportAccount collects everything stored about the user for them to download.
+func (u *userService) ExportAccount(ctx context.Context, user *domain.User) (*domain.AccountExport, error) {
+	export := &domain.AccountExport{
+		ExportedAt: time.Now(),
+	}
+
+	var err error
+
+	if export.User, err = u.userRepository.GetUserById(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	permissions, err := u.permissions.GetAllForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.Permissions = *permissions
+
+	if export.Sessions, err = u.tokenRepository.GetSessionsForUser(ctx, user.ID, ""); err != nil {
+		return nil, err
+	}
+
+	if export.ApiKeys, err = u.apiKeyRepository.GetApiKeysForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	if export.Ratings, err = u.accountRepository.GetRatingsForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	if export.Reviews, err = u.accountRepository.GetReviewsForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	if export.Lists, err = u.accountRepository.GetListsWithItemsForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	if export.History, err = u.accountRepository.GetHistoryForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	export.Deletion, err = u.accountRepository.GetDeletion(ctx, user.ID)
+	if err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// DeleteAccount schedules the account to be purged once the grace period is over. Every
+// other session is signed out straight away; the current one is kept so the user can still
+// cancel.
+func (u *userService) DeleteAccount(ctx context.Context, user *domain.User, currentToken string, input *dto.DeleteAccount) (*domain.AccountDeletion, error) {
+	v := validator.New()
+
+	if v.Check(input.Password != "", "password", "must be provided"); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	account, err := u.userRepository.GetUserById(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := account.Password.Matches(input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if !match {
+		return nil, ErrInvalidCredentials
+	}
+
+	currentFamily, err := u.sessionFamily(ctx, currentToken)
+	if err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	deletion := &domain.AccountDeletion{
+		UserID:     account.ID,
+		PurgeAfter: time.Now().Add(config.AppConfig.Account.DeletionGracePeriod),
+	}
+
+	err = u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		if err := u.accountRepository.WithTx(ctx, tx).ScheduleDeletion(ctx, deletion); err != nil {
+			switch {
+			case errors.Is(err, repository.ErrEditConflict):
+				return ErrDeletionScheduled
+			default:
+				return err
+			}
+		}
+
+		revoked, err := txTokenRepo.DeleteOtherFamilies(ctx, account.ID, currentFamily)
+		if err != nil {
+			return err
+		}
+
+		return u.revokeSignedSessions(ctx, txTokenRepo, revoked...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	background(func() {
+		data := map[string]any{
+			"purgeAfter": deletion.PurgeAfter.UTC().Format(time.RFC1123),
+		}
+
+		err := u.notification.Send(account.Email, "account_deletion.tmpl", data)
+		if err != nil {
+			slg.Logger.Error(err.Error())
+		}
+	})
+
+	return deletion, nil
+}
+
+func (u *userService) CancelAccountDeletion(ctx context.Context, user *domain.User) error {
+	return u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return u.accountRepository.WithTx(ctx, tx).CancelDeletion(ctx, user.ID)
+	})
+}
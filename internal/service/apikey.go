@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
+)
+
+type ApiKeyService interface {
+	CreateApiKey(ctx context.Context, user *domain.User, input *dto.ApiKey) (*domain.ApiKey, error)
+	GetApiKey(ctx context.Context, user *domain.User, id int64) (*domain.ApiKey, error)
+	GetApiKeys(ctx context.Context, user *domain.User) ([]*domain.ApiKey, error)
+	UpdateApiKey(ctx context.Context, user *domain.User, id int64, input *dto.UpdateApiKey) (*domain.ApiKey, error)
+	DeleteApiKey(ctx context.Context, user *domain.User, id int64) error
+}
+
+type apiKeyService struct {
+	apiKeyRepository     repository.ApiKeyRepository
+	permissionRepository repository.PermissionRepository
+	txService            transaction.TxService
+}
+
+// CreateApiKey returns the new key with its plaintext, which is not stored and cannot be
+// retrieved again.
+func (a *apiKeyService) CreateApiKey(ctx context.Context, user *domain.User, input *dto.ApiKey) (*domain.ApiKey, error) {
+	key := utils.GenerateApiKey(user.ID)
+	key.Name = input.Name
+	key.Permissions = input.Permissions
+	key.Expiry = input.Expiry
+
+	if err := a.validate(ctx, user, key); err != nil {
+		return nil, err
+	}
+
+	var createdKey *domain.ApiKey
+	err := a.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		createdKey, err = a.apiKeyRepository.WithTx(ctx, tx).CreateApiKey(ctx, key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createdKey, nil
+}
+
+func (a *apiKeyService) GetApiKey(ctx context.Context, user *domain.User, id int64) (*domain.ApiKey, error) {
+	return a.apiKeyRepository.GetApiKeyById(ctx, user.ID, id)
+}
+
+func (a *apiKeyService) GetApiKeys(ctx context.Context, user *domain.User) ([]*domain.ApiKey, error) {
+	return a.apiKeyRepository.GetApiKeysForUser(ctx, user.ID)
+}
+
+func (a *apiKeyService) UpdateApiKey(ctx context.Context, user *domain.User, id int64, input *dto.UpdateApiKey) (*domain.ApiKey, error) {
+	var updatedKey *domain.ApiKey
+
+	err := a.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := a.apiKeyRepository.WithTx(ctx, tx)
+
+		key, err := txRepo.GetApiKeyById(ctx, user.ID, id)
+		if err != nil {
+			return err
+		}
+
+		if input.Name != nil {
+			key.Name = *input.Name
+		}
+		if input.Permissions != nil {
+			key.Permissions = *input.Permissions
+		}
+		if input.Expiry != nil {
+			key.Expiry = input.Expiry
+		}
+
+		if err = a.validate(ctx, user, key); err != nil {
+			return err
+		}
+
+		updatedKey, err = txRepo.UpdateApiKey(ctx, key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedKey, nil
+}
+
+func (a *apiKeyService) DeleteApiKey(ctx context.Context, user *domain.User, id int64) error {
+	return a.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return a.apiKeyRepository.WithTx(ctx, tx).DeleteApiKey(ctx, user.ID, id)
+	})
+}
+
+func (a *apiKeyService) validate(ctx context.Context, user *domain.User, key *domain.ApiKey) error {
+	permissions, err := a.permissionRepository.GetAllForUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	v := validator.New()
+	if domain.ValidateApiKey(v, key, *permissions); !v.Valid() {
+		return v.GetValidationError()
+	}
+
+	return nil
+}
+
+func NewApiKeyService(apiKeyRepository repository.ApiKeyRepository, permissionRepository repository.PermissionRepository, txService transaction.TxService) ApiKeyService {
+	return &apiKeyService{
+		apiKeyRepository:     apiKeyRepository,
+		permissionRepository: permissionRepository,
+		txService:            txService,
+	}
+}
@@ -0,0 +1,13 @@
+package service
+
+import "errors"
+
+var (
+	ErrNotPermitted         = errors.New("not permitted")
+	ErrInvalidToken         = errors.New("invalid or expired token")
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrDeletionScheduled    = errors.New("account deletion is already scheduled")
+)
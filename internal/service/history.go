@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"time"
+)
+
+type HistoryService interface {
+	RecordWatch(ctx context.Context, user *domain.User, permissions domain.Permissions, input *dto.HistoryEntry) (*domain.HistoryEntry, error)
+	GetHistory(ctx context.Context, user *domain.User, filters domain.Filters) ([]*domain.HistoryEntry, domain.Metadata, error)
+	DeleteEntry(ctx context.Context, user *domain.User, id int64) error
+	GetStats(ctx context.Context, user *domain.User) (*domain.WatchStats, error)
+}
+
+type historyService struct {
+	historyRepository repository.HistoryRepository
+	ratingRepository  repository.RatingRepository
+	movieRepository   repository.MovieRepository
+	txService         transaction.TxService
+}
+
+func (h *historyService) RecordWatch(ctx context.Context, user *domain.User, permissions domain.Permissions, input *dto.HistoryEntry) (*domain.HistoryEntry, error) {
+	v := validator.New()
+
+	entry := &domain.HistoryEntry{
+		UserID:    user.ID,
+		MovieID:   input.MovieID,
+		WatchedAt: time.Now(),
+		Rating:    input.Rating,
+	}
+
+	if input.WatchedAt != nil {
+		entry.WatchedAt = *input.WatchedAt
+	}
+
+	if domain.ValidateHistoryEntry(v, entry); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	// Rating from the diary goes through the same permission as PUT /v1/movies/:id/rating.
+	// permissions are those of the request, so an API key is held to its own restriction.
+	if entry.Rating != 0 && !permissions.Include("ratings:write") {
+		return nil, ErrNotPermitted
+	}
+
+	err := h.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		if _, err := h.historyRepository.WithTx(ctx, tx).CreateEntry(ctx, entry); err != nil {
+			return err
+		}
+
+		if entry.Rating == 0 {
+			return nil
+		}
+
+		rating := &domain.Rating{
+			UserID:  user.ID,
+			MovieID: entry.MovieID,
+			Score:   entry.Rating,
+		}
+
+		return applyRating(ctx, h.ratingRepository.WithTx(ctx, tx), h.movieRepository.WithTx(ctx, tx), rating)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (h *historyService) GetHistory(ctx context.Context, user *domain.User, filters domain.Filters) ([]*domain.HistoryEntry, domain.Metadata, error) {
+	return h.historyRepository.GetEntries(ctx, user.ID, filters)
+}
+
+func (h *historyService) DeleteEntry(ctx context.Context, user *domain.User, id int64) error {
+	return h.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return h.historyRepository.WithTx(ctx, tx).DeleteEntry(ctx, user.ID, id)
+	})
+}
+
+func (h *historyService) GetStats(ctx context.Context, user *domain.User) (*domain.WatchStats, error) {
+	return h.historyRepository.GetStats(ctx, user.ID)
+}
+
+func NewHistoryService(historyRepository repository.HistoryRepository, ratingRepository repository.RatingRepository, movieRepository repository.MovieRepository, txService transaction.TxService) HistoryService {
+	return &historyService{
+		historyRepository: historyRepository,
+		ratingRepository:  ratingRepository,
+		movieRepository:   movieRepository,
+		txService:         txService,
+	}
+}
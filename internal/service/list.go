@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
+)
+
+type ListService interface {
+	CreateList(ctx context.Context, user *domain.User, input *dto.List) (*domain.List, error)
+	GetList(ctx context.Context, user *domain.User, id int64) (*domain.List, error)
+	GetListBySlug(ctx context.Context, viewer *domain.User, slug string) (*domain.List, error)
+	GetMyLists(ctx context.Context, user *domain.User, filters domain.Filters) ([]*domain.List, domain.Metadata, error)
+	GetPublicLists(ctx context.Context, userID int64, filters domain.Filters) ([]*domain.List, domain.Metadata, error)
+	UpdateList(ctx context.Context, user *domain.User, id int64, input *dto.UpdateList) (*domain.List, error)
+	DeleteList(ctx context.Context, user *domain.User, id int64) error
+	AddMovie(ctx context.Context, user *domain.User, id int64, input *dto.ListItem) (*domain.List, error)
+	RemoveMovie(ctx context.Context, user *domain.User, id, movieID int64) (*domain.List, error)
+	ReorderMovies(ctx context.Context, user *domain.User, id int64, input *dto.ReorderList) (*domain.List, error)
+}
+
+type listService struct {
+	listRepository repository.ListRepository
+	txService      transaction.TxService
+}
+
+func (l *listService) CreateList(ctx context.Context, user *domain.User, input *dto.List) (*domain.List, error) {
+	v := validator.New()
+
+	list := &domain.List{
+		UserID:      user.ID,
+		Name:        input.Name,
+		Description: input.Description,
+		Visibility:  input.Visibility,
+		Slug:        utils.GenerateSlug(input.Name),
+	}
+
+	if list.Visibility == "" {
+		list.Visibility = domain.VisibilityPrivate
+	}
+
+	if domain.ValidateList(v, list); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var createdList *domain.List
+	err := l.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		createdList, err = l.listRepository.WithTx(ctx, tx).CreateList(ctx, list)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	createdList.Items = []*domain.ListItem{}
+
+	return createdList, nil
+}
+
+func (l *listService) GetList(ctx context.Context, user *domain.User, id int64) (*domain.List, error) {
+	list, err := l.fetchOwnList(ctx, l.listRepository, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.withItems(ctx, l.listRepository, list)
+}
+
+func (l *listService) GetListBySlug(ctx context.Context, viewer *domain.User, slug string) (*domain.List, error) {
+	list, err := l.listRepository.GetListBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if !list.VisibleTo(viewer) {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	return l.withItems(ctx, l.listRepository, list)
+}
+
+func (l *listService) GetMyLists(ctx context.Context, user *domain.User, filters domain.Filters) ([]*domain.List, domain.Metadata, error) {
+	return l.listRepository.GetListsForUser(ctx, user.ID, "", filters)
+}
+
+func (l *listService) GetPublicLists(ctx context.Context, userID int64, filters domain.Filters) ([]*domain.List, domain.Metadata, error) {
+	return l.listRepository.GetListsForUser(ctx, userID, domain.VisibilityPublic, filters)
+}
+
+func (l *listService) UpdateList(ctx context.Context, user *domain.User, id int64, input *dto.UpdateList) (*domain.List, error) {
+	var updatedList *domain.List
+
+	err := l.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := l.listRepository.WithTx(ctx, tx)
+
+		list, err := l.fetchOwnList(ctx, txRepo, user, id)
+		if err != nil {
+			return err
+		}
+
+		if input.Name != nil {
+			list.Name = *input.Name
+		}
+		if input.Description != nil {
+			list.Description = *input.Description
+		}
+		if input.Visibility != nil {
+			list.Visibility = *input.Visibility
+		}
+
+		v := validator.New()
+		if domain.ValidateList(v, list); !v.Valid() {
+			return v.GetValidationError()
+		}
+
+		if list, err = txRepo.UpdateList(ctx, list); err != nil {
+			return err
+		}
+
+		updatedList, err = l.withItems(ctx, txRepo, list)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedList, nil
+}
+
+func (l *listService) DeleteList(ctx context.Context, user *domain.User, id int64) error {
+	return l.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := l.listRepository.WithTx(ctx, tx)
+
+		if _, err := l.fetchOwnList(ctx, txRepo, user, id); err != nil {
+			return err
+		}
+
+		return txRepo.DeleteList(ctx, id)
+	})
+}
+
+func (l *listService) AddMovie(ctx context.Context, user *domain.User, id int64, input *dto.ListItem) (*domain.List, error) {
+	v := validator.New()
+
+	if v.Check(input.MovieID > 0, "movie_id", "must be provided"); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	return l.modifyItems(ctx, user, id, func(repo repository.ListRepository, list *domain.List) error {
+		return repo.AddItem(ctx, list.ID, input.MovieID)
+	})
+}
+
+func (l *listService) RemoveMovie(ctx context.Context, user *domain.User, id, movieID int64) (*domain.List, error) {
+	return l.modifyItems(ctx, user, id, func(repo repository.ListRepository, list *domain.List) error {
+		return repo.RemoveItem(ctx, list.ID, movieID)
+	})
+}
+
+func (l *listService) ReorderMovies(ctx context.Context, user *domain.User, id int64, input *dto.ReorderList) (*domain.List, error) {
+	return l.modifyItems(ctx, user, id, func(repo repository.ListRepository, list *domain.List) error {
+		items, err := repo.GetItems(ctx, list.ID)
+		if err != nil {
+			return err
+		}
+
+		v := validator.New()
+		if domain.ValidateListOrder(v, items, input.MovieIDs); !v.Valid() {
+			return v.GetValidationError()
+		}
+
+		return repo.SetPositions(ctx, list.ID, input.MovieIDs)
+	})
+}
+
+// modifyItems runs fn against one of the user's lists inside a transaction and returns the
+// list with its items as they are after the change.
+func (l *listService) modifyItems(ctx context.Context, user *domain.User, id int64, fn func(repository.ListRepository, *domain.List) error) (*domain.List, error) {
+	var modifiedList *domain.List
+
+	err := l.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := l.listRepository.WithTx(ctx, tx)
+
+		list, err := l.fetchOwnList(ctx, txRepo, user, id)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(txRepo, list); err != nil {
+			return err
+		}
+
+		modifiedList, err = l.withItems(ctx, txRepo, list)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return modifiedList, nil
+}
+
+// fetchOwnList loads a list and hides it from anyone but its owner.
+func (l *listService) fetchOwnList(ctx context.Context, repo repository.ListRepository, user *domain.User, id int64) (*domain.List, error) {
+	list, err := repo.GetListById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.UserID != user.ID {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	return list, nil
+}
+
+func (l *listService) withItems(ctx context.Context, repo repository.ListRepository, list *domain.List) (*domain.List, error) {
+	items, err := repo.GetItems(ctx, list.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	list.Items = items
+
+	return list, nil
+}
+
+func NewListService(listRepository repository.ListRepository, txService transaction.TxService) ListService {
+	return &listService{
+		listRepository: listRepository,
+		txService:      txService,
+	}
+}
@@ -9,29 +9,34 @@ import (
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
 	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
 )
 
 type MovieService interface {
-	CreateMovie(ctx context.Context, input *dto.Movie) (*domain.Movie, error)
+	CreateMovie(ctx context.Context, user *domain.User, input *dto.Movie) (*domain.Movie, error)
 	GetMovieById(ctx context.Context, id int64) (*domain.Movie, error)
-	GetMovies(ctx context.Context) ([]*domain.Movie, error)
-	UpdateMovie(ctx context.Context, id int64, input *dto.UpdateMovie) (*domain.Movie, error)
-	DeleteMovie(ctx context.Context, id int64) error
+	GetMovies(ctx context.Context, title string, genres []string, filters domain.Filters) ([]*domain.Movie, domain.Metadata, error)
+	GetMoviesByCursor(ctx context.Context, title string, genres []string, filters domain.Filters, cursor *domain.Cursor) ([]*domain.Movie, domain.CursorMetadata, error)
+	SearchMovies(ctx context.Context, query, mode string, filters domain.Filters) ([]*domain.MovieSearchResult, domain.Metadata, error)
+	UpdateMovie(ctx context.Context, user *domain.User, permissions domain.Permissions, id int64, input *dto.UpdateMovie) (*domain.Movie, error)
+	DeleteMovie(ctx context.Context, user *domain.User, permissions domain.Permissions, id int64) error
 }
 
 type movieService struct {
 	movieRepository repository.MovieRepository
+	listRepository  repository.ListRepository
 	txService       transaction.TxService
 }
 
-func (m *movieService) CreateMovie(ctx context.Context, input *dto.Movie) (*domain.Movie, error) {
+func (m *movieService) CreateMovie(ctx context.Context, user *domain.User, input *dto.Movie) (*domain.Movie, error) {
 	v := validator.New()
 
 	movie := &domain.Movie{
-		Title:   input.Title,
-		Year:    input.Year,
-		Runtime: input.Runtime,
-		Genres:  input.Genres,
+		Title:     input.Title,
+		Year:      input.Year,
+		Runtime:   input.Runtime,
+		Genres:    input.Genres,
+		CreatedBy: &user.ID,
 	}
 
 	domain.ValidateMovie(v, movie)
@@ -65,15 +70,54 @@ func (m *movieService) GetMovieById(ctx context.Context, id int64) (*domain.Movi
 	return movie, nil
 }
 
-func (m *movieService) GetMovies(ctx context.Context) ([]*domain.Movie, error) {
-	return m.movieRepository.GetMovies(ctx)
+func (m *movieService) GetMovies(ctx context.Context, title string, genres []string, filters domain.Filters) ([]*domain.Movie, domain.Metadata, error) {
+	return m.movieRepository.GetMovies(ctx, title, genres, filters)
+}
+
+func (m *movieService) GetMoviesByCursor(ctx context.Context, title string, genres []string, filters domain.Filters, cursor *domain.Cursor) ([]*domain.Movie, domain.CursorMetadata, error) {
+	movies, hasMore, err := m.movieRepository.GetMoviesByCursor(ctx, title, genres, filters, cursor)
+	if err != nil {
+		return nil, domain.CursorMetadata{}, err
+	}
+
+	metadata := domain.CursorMetadata{PageSize: filters.PageSize}
+	if len(movies) == 0 {
+		return movies, metadata, nil
+	}
+
+	column := filters.SortColumn()
+	backward := cursor != nil && cursor.Backward
+
+	if hasMore || backward {
+		last := movies[len(movies)-1]
+		metadata.NextCursor, err = utils.EncodeCursor(&domain.Cursor{Sort: filters.Sort, Value: domain.MovieSortValue(last, column), ID: last.ID})
+		if err != nil {
+			return nil, domain.CursorMetadata{}, err
+		}
+	}
+
+	if cursor != nil && (hasMore || !backward) {
+		first := movies[0]
+		metadata.PrevCursor, err = utils.EncodeCursor(&domain.Cursor{Sort: filters.Sort, Value: domain.MovieSortValue(first, column), ID: first.ID, Backward: true})
+		if err != nil {
+			return nil, domain.CursorMetadata{}, err
+		}
+	}
+
+	return movies, metadata, nil
+}
+
+func (m *movieService) SearchMovies(ctx context.Context, query, mode string, filters domain.Filters) ([]*domain.MovieSearchResult, domain.Metadata, error) {
+	return m.movieRepository.SearchMovies(ctx, query, mode, filters)
 }
 
 func (m *movieService) fetchMovie(ctx context.Context, id int64) (*domain.Movie, error) {
 	return m.movieRepository.GetMovieById(ctx, id)
 }
 
-func (m *movieService) UpdateMovie(ctx context.Context, id int64, input *dto.UpdateMovie) (*domain.Movie, error) {
+// UpdateMovie applies the changes if the user's permissions allow them to edit this movie.
+// The route only checks for movies:write:own, so ownership has to be decided here.
+func (m *movieService) UpdateMovie(ctx context.Context, user *domain.User, permissions domain.Permissions, id int64, input *dto.UpdateMovie) (*domain.Movie, error) {
 	var updatedMovie *domain.Movie
 
 	err := m.txService.WithTx(ctx, func(tx *sql.Tx) error {
@@ -84,6 +128,10 @@ func (m *movieService) UpdateMovie(ctx context.Context, id int64, input *dto.Upd
 			return err
 		}
 
+		if !movie.EditableBy(user, permissions) {
+			return ErrNotPermitted
+		}
+
 		if input.Title != nil {
 			movie.Title = *input.Title
 		}
@@ -114,16 +162,32 @@ func (m *movieService) UpdateMovie(ctx context.Context, id int64, input *dto.Upd
 	return updatedMovie, nil
 }
 
-func (m *movieService) DeleteMovie(ctx context.Context, id int64) error {
+func (m *movieService) DeleteMovie(ctx context.Context, user *domain.User, permissions domain.Permissions, id int64) error {
 	return m.txService.WithTx(ctx, func(tx *sql.Tx) error {
 		txRepo := m.movieRepository.WithTx(ctx, tx)
+		txListRepo := m.listRepository.WithTx(ctx, tx)
+
+		movie, err := txRepo.GetMovieById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !movie.EditableBy(user, permissions) {
+			return ErrNotPermitted
+		}
+
+		if err := txListRepo.DeleteItemsForMovie(ctx, id); err != nil {
+			return err
+		}
+
 		return txRepo.DeleteMovie(ctx, id)
 	})
 }
 
-func NewMovieService(movieRepository repository.MovieRepository, txService transaction.TxService) MovieService {
+func NewMovieService(movieRepository repository.MovieRepository, listRepository repository.ListRepository, txService transaction.TxService) MovieService {
 	return &movieService{
 		movieRepository: movieRepository,
+		listRepository:  listRepository,
 		txService:       txService,
 	}
 }
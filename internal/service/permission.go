@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+)
+
+type PermissionService interface {
+	GetPermissions(ctx context.Context) ([]*domain.Permission, error)
+	CreatePermission(ctx context.Context, actor *domain.User, input *dto.Permission) (*domain.Permission, error)
+	GetUserPermissions(ctx context.Context, userID int64) (domain.Permissions, error)
+	GrantPermissions(ctx context.Context, actor *domain.User, userID int64, input *dto.PermissionCodes) (domain.Permissions, error)
+	RevokePermission(ctx context.Context, actor *domain.User, userID int64, code string) error
+	GetAudit(ctx context.Context, userID int64, filters domain.Filters) ([]*domain.PermissionAuditEntry, domain.Metadata, error)
+	GetRoles(ctx context.Context) ([]*domain.Role, error)
+	CreateRole(ctx context.Context, actor *domain.User, input *dto.Role) (*domain.Role, error)
+	GrantRolePermissions(ctx context.Context, actor *domain.User, name string, input *dto.PermissionCodes) (*domain.Role, error)
+	RevokeRolePermission(ctx context.Context, actor *domain.User, name, code string) error
+	GetUserRoles(ctx context.Context, userID int64) ([]string, error)
+	AssignRole(ctx context.Context, actor *domain.User, userID int64, input *dto.RoleAssignment) ([]string, error)
+	UnassignRole(ctx context.Context, actor *domain.User, userID int64, name string) error
+}
+
+type permissionService struct {
+	permissionRepository repository.PermissionRepository
+	userRepository       repository.UserRepository
+	txService            transaction.TxService
+}
+
+func (p *permissionService) GetPermissions(ctx context.Context) ([]*domain.Permission, error) {
+	return p.permissionRepository.GetAll(ctx)
+}
+
+func (p *permissionService) CreatePermission(ctx context.Context, actor *domain.User, input *dto.Permission) (*domain.Permission, error) {
+	v := validator.New()
+
+	if domain.ValidatePermissionCode(v, input.Code); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	permission := &domain.Permission{
+		Code: input.Code,
+	}
+
+	err := p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := p.permissionRepository.WithTx(ctx, tx)
+
+		if err := txRepo.Insert(ctx, permission); err != nil {
+			return err
+		}
+
+		return txRepo.InsertAudit(ctx, &domain.PermissionAuditEntry{
+			ActorID: &actor.ID,
+			Action:  domain.PermissionActionCreate,
+			Code:    permission.Code,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return permission, nil
+}
+
+func (p *permissionService) GetUserPermissions(ctx context.Context, userID int64) (domain.Permissions, error) {
+	if _, err := p.userRepository.GetUserById(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	permissions, err := p.permissionRepository.GetAllForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return *permissions, nil
+}
+
+// GrantPermissions gives the user each of the codes. Codes the user already has are left
+// alone and, since nothing changed, not audited.
+func (p *permissionService) GrantPermissions(ctx context.Context, actor *domain.User, userID int64, input *dto.PermissionCodes) (domain.Permissions, error) {
+	v := validator.New()
+
+	if domain.ValidatePermissionCodes(v, input.Codes); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	if err := p.checkCodesExist(ctx, input.Codes); err != nil {
+		return nil, err
+	}
+
+	var permissions *domain.Permissions
+
+	err := p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := p.permissionRepository.WithTx(ctx, tx)
+
+		if _, err := p.userRepository.WithTx(ctx, tx).GetUserById(ctx, userID); err != nil {
+			return err
+		}
+
+		for _, code := range input.Codes {
+			granted, err := txRepo.GrantForUser(ctx, userID, code)
+			if err != nil {
+				return err
+			}
+
+			if !granted {
+				continue
+			}
+
+			err = txRepo.InsertAudit(ctx, &domain.PermissionAuditEntry{
+				ActorID: &actor.ID,
+				Action:  domain.PermissionActionGrant,
+				Code:    code,
+				UserID:  &userID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		permissions, err = txRepo.GetAllForUser(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return *permissions, nil
+}
+
+// RevokePermission takes the code away from the user, failing with ErrRecordNotFound if
+// the user does not exist or does not have it directly. Codes the user has through a role
+// are removed by unassigning or changing the role.
+func (p *permissionService) RevokePermission(ctx context.Context, actor *domain.User, userID int64, code string) error {
+	return p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := p.permissionRepository.WithTx(ctx, tx)
+
+		revoked, err := txRepo.RevokeForUser(ctx, userID, code)
+		if err != nil {
+			return err
+		}
+
+		if !revoked {
+			return repository.ErrRecordNotFound
+		}
+
+		return txRepo.InsertAudit(ctx, &domain.PermissionAuditEntry{
+			ActorID: &actor.ID,
+			Action:  domain.PermissionActionRevoke,
+			Code:    code,
+			UserID:  &userID,
+		})
+	})
+}
+
+func (p *permissionService) GetAudit(ctx context.Context, userID int64, filters domain.Filters) ([]*domain.PermissionAuditEntry, domain.Metadata, error) {
+	return p.permissionRepository.GetAudit(ctx, userID, filters)
+}
+
+func (p *permissionService) GetRoles(ctx context.Context) ([]*domain.Role, error) {
+	return p.permissionRepository.GetRoles(ctx)
+}
+
+func (p *permissionService) CreateRole(ctx context.Context, actor *domain.User, input *dto.Role) (*domain.Role, error) {
+	role := &domain.Role{
+		Name:        input.Name,
+		Permissions: input.Codes,
+	}
+
+	if role.Permissions == nil {
+		role.Permissions = domain.Permissions{}
+	}
+
+	v := validator.New()
+
+	if domain.ValidateRole(v, role); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	if err := p.checkCodesExist(ctx, role.Permissions); err != nil {
+		return nil, err
+	}
+
+	err := p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := p.permissionRepository.WithTx(ctx, tx)
+
+		if err := txRepo.InsertRole(ctx, role); err != nil {
+			return err
+		}
+
+		err := txRepo.InsertAudit(ctx, &domain.PermissionAuditEntry{
+			ActorID: &actor.ID,
+			Action:  domain.PermissionActionCreateRole,
+			Role:    role.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		return p.grantForRole(ctx, txRepo, actor, role, role.Permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (p *permissionService) GrantRolePermissions(ctx context.Context, actor *domain.User, name string, input *dto.PermissionCodes) (*domain.Role, error) {
+	v := validator.New()
+
+	if domain.ValidatePermissionCodes(v, input.Codes); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	if err := p.checkCodesExist(ctx, input.Codes); err != nil {
+		return nil, err
+	}
+
+	var role *domain.Role
+
+	err := p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := p.permissionRepository.WithTx(ctx, tx)
+
+		var err error
+		if role, err = txRepo.GetRole(ctx, name); err != nil {
+			return err
+		}
+
+		if err = p.grantForRole(ctx, txRepo, actor, role, input.Codes); err != nil {
+			return err
+		}
+
+		role, err = txRepo.GetRole(ctx, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (p *permissionService) RevokeRolePermission(ctx context.Context, actor *domain.User, name, code string) error {
+	return p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := p.permissionRepository.WithTx(ctx, tx)
+
+		role, err := txRepo.GetRole(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		revoked, err := txRepo.RevokeForRole(ctx, role.ID, code)
+		if err != nil {
+			return err
+		}
+
+		if !revoked {
+			return repository.ErrRecordNotFound
+		}
+
+		return txRepo.InsertAudit(ctx, &domain.PermissionAuditEntry{
+			ActorID: &actor.ID,
+			Action:  domain.PermissionActionRevoke,
+			Code:    code,
+			Role:    role.Name,
+		})
+	})
+}
+
+func (p *permissionService) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	if _, err := p.userRepository.GetUserById(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return p.permissionRepository.GetRolesForUser(ctx, userID)
+}
+
+func (p *permissionService) AssignRole(ctx context.Context, actor *domain.User, userID int64, input *dto.RoleAssignment) ([]string, error) {
+	v := validator.New()
+
+	if v.Check(input.Role != "", "role", "must be provided"); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var roles []string
+
+	err := p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := p.permissionRepository.WithTx(ctx, tx)
+
+		if _, err := p.userRepository.WithTx(ctx, tx).GetUserById(ctx, userID); err != nil {
+			return err
+		}
+
+		assigned, err := txRepo.AssignRole(ctx, userID, input.Role)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrRecordNotFound):
+				v.AddError("role", "must be an existing role")
+				return v.GetValidationError()
+			default:
+				return err
+			}
+		}
+
+		if assigned {
+			err = txRepo.InsertAudit(ctx, &domain.PermissionAuditEntry{
+				ActorID: &actor.ID,
+				Action:  domain.PermissionActionAssign,
+				Role:    input.Role,
+				UserID:  &userID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		roles, err = txRepo.GetRolesForUser(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (p *permissionService) UnassignRole(ctx context.Context, actor *domain.User, userID int64, name string) error {
+	return p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := p.permissionRepository.WithTx(ctx, tx)
+
+		unassigned, err := txRepo.UnassignRole(ctx, userID, name)
+		if err != nil {
+			return err
+		}
+
+		if !unassigned {
+			return repository.ErrRecordNotFound
+		}
+
+		return txRepo.InsertAudit(ctx, &domain.PermissionAuditEntry{
+			ActorID: &actor.ID,
+			Action:  domain.PermissionActionUnassign,
+			Role:    name,
+			UserID:  &userID,
+		})
+	})
+}
+
+// checkCodesExist reports codes that have not been created as a validation error, rather
+// than letting them be silently skipped by the grant queries.
+func (p *permissionService) checkCodesExist(ctx context.Context, codes []string) error {
+	v := validator.New()
+
+	for _, code := range codes {
+		_, err := p.permissionRepository.GetPermission(ctx, code)
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			v.AddError("codes", "must only contain existing permission codes")
+		case err != nil:
+			return err
+		}
+	}
+
+	return v.GetValidationError()
+}
+
+// grantForRole adds codes to the role, auditing the ones it did not already have.
+func (p *permissionService) grantForRole(ctx context.Context, txRepo repository.PermissionRepository, actor *domain.User, role *domain.Role, codes []string) error {
+	for _, code := range codes {
+		granted, err := txRepo.GrantForRole(ctx, role.ID, code)
+		if err != nil {
+			return err
+		}
+
+		if !granted {
+			continue
+		}
+
+		err = txRepo.InsertAudit(ctx, &domain.PermissionAuditEntry{
+			ActorID: &actor.ID,
+			Action:  domain.PermissionActionGrant,
+			Code:    code,
+			Role:    role.Name,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func NewPermissionService(permissionRepository repository.PermissionRepository, userRepository repository.UserRepository, txService transaction.TxService) PermissionService {
+	return &permissionService{
+		permissionRepository: permissionRepository,
+		userRepository:       userRepository,
+		txService:            txService,
+	}
+}
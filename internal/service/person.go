@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+)
+
+type PersonService interface {
+	CreatePerson(ctx context.Context, input *dto.Person) (*domain.Person, error)
+	GetPersonById(ctx context.Context, id int64) (*domain.Person, error)
+	GetPeople(ctx context.Context, name string, filters domain.Filters) ([]*domain.Person, domain.Metadata, error)
+	UpdatePerson(ctx context.Context, id int64, input *dto.UpdatePerson) (*domain.Person, error)
+	DeletePerson(ctx context.Context, id int64) error
+	AddCredit(ctx context.Context, movieID int64, input *dto.Credit) (*domain.Credit, error)
+	RemoveCredit(ctx context.Context, movieID, creditID int64) error
+	GetMovieCredits(ctx context.Context, movieID int64) ([]*domain.Credit, error)
+	GetFilmography(ctx context.Context, personID int64) ([]*domain.Credit, error)
+}
+
+type personService struct {
+	personRepository repository.PersonRepository
+	movieRepository  repository.MovieRepository
+	txService        transaction.TxService
+}
+
+func (p *personService) CreatePerson(ctx context.Context, input *dto.Person) (*domain.Person, error) {
+	v := validator.New()
+
+	person := &domain.Person{
+		Name:      input.Name,
+		Biography: input.Biography,
+	}
+
+	if domain.ValidatePerson(v, person); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var createdPerson *domain.Person
+	err := p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		createdPerson, err = p.personRepository.WithTx(ctx, tx).CreatePerson(ctx, person)
+		return err
+	})
+	if err != nil {
+		slg.Logger.Error("error creating person", "error", err)
+		return nil, err
+	}
+
+	return createdPerson, nil
+}
+
+func (p *personService) GetPersonById(ctx context.Context, id int64) (*domain.Person, error) {
+	return p.personRepository.GetPersonById(ctx, id)
+}
+
+func (p *personService) GetPeople(ctx context.Context, name string, filters domain.Filters) ([]*domain.Person, domain.Metadata, error) {
+	return p.personRepository.GetPeople(ctx, name, filters)
+}
+
+func (p *personService) UpdatePerson(ctx context.Context, id int64, input *dto.UpdatePerson) (*domain.Person, error) {
+	var updatedPerson *domain.Person
+
+	err := p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := p.personRepository.WithTx(ctx, tx)
+
+		person, err := txRepo.GetPersonById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if input.Name != nil {
+			person.Name = *input.Name
+		}
+		if input.Biography != nil {
+			person.Biography = *input.Biography
+		}
+
+		v := validator.New()
+		if domain.ValidatePerson(v, person); !v.Valid() {
+			return v.GetValidationError()
+		}
+
+		updatedPerson, err = txRepo.UpdatePerson(ctx, person)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedPerson, nil
+}
+
+func (p *personService) DeletePerson(ctx context.Context, id int64) error {
+	return p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return p.personRepository.WithTx(ctx, tx).DeletePerson(ctx, id)
+	})
+}
+
+func (p *personService) AddCredit(ctx context.Context, movieID int64, input *dto.Credit) (*domain.Credit, error) {
+	v := validator.New()
+
+	credit := &domain.Credit{
+		MovieID:       movieID,
+		PersonID:      input.PersonID,
+		Role:          input.Role,
+		CharacterName: input.CharacterName,
+		BillingOrder:  input.BillingOrder,
+	}
+
+	if domain.ValidateCredit(v, credit); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var createdCredit *domain.Credit
+	err := p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		createdCredit, err = p.personRepository.WithTx(ctx, tx).CreateCredit(ctx, credit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createdCredit, nil
+}
+
+func (p *personService) RemoveCredit(ctx context.Context, movieID, creditID int64) error {
+	return p.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return p.personRepository.WithTx(ctx, tx).DeleteCredit(ctx, movieID, creditID)
+	})
+}
+
+func (p *personService) GetMovieCredits(ctx context.Context, movieID int64) ([]*domain.Credit, error) {
+	if _, err := p.movieRepository.GetMovieById(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	return p.personRepository.GetCreditsForMovie(ctx, movieID)
+}
+
+func (p *personService) GetFilmography(ctx context.Context, personID int64) ([]*domain.Credit, error) {
+	if _, err := p.personRepository.GetPersonById(ctx, personID); err != nil {
+		return nil, err
+	}
+
+	return p.personRepository.GetFilmography(ctx, personID)
+}
+
+func NewPersonService(personRepository repository.PersonRepository, movieRepository repository.MovieRepository, txService transaction.TxService) PersonService {
+	return &personService{
+		personRepository: personRepository,
+		movieRepository:  movieRepository,
+		txService:        txService,
+	}
+}
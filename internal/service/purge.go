@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+	"time"
+)
+
+const purgeBatchSize = 100
+
+// AccountPurger hard-deletes accounts whose deletion grace period has ended. It runs as a
+// background job so that no request waits on the cascade.
+type AccountPurger struct {
+	accountRepository repository.AccountRepository
+	movieRepository   repository.MovieRepository
+	txService         transaction.TxService
+}
+
+// Run purges due accounts every interval until ctx is cancelled.
+func (a *AccountPurger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.Purge(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			slg.Logger.Error("failed to purge deleted accounts", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes every account that is currently due, one transaction per account so that a
+// failure only holds back the account it happened on.
+func (a *AccountPurger) Purge(ctx context.Context) error {
+	for {
+		userIDs, err := a.accountRepository.GetDueDeletions(ctx, time.Now(), purgeBatchSize)
+		if err != nil {
+			return err
+		}
+
+		purged := 0
+
+		for _, userID := range userIDs {
+			err = a.txService.WithTx(ctx, func(tx *sql.Tx) error {
+				movieIDs, err := a.accountRepository.WithTx(ctx, tx).PurgeUser(ctx, userID)
+				if err != nil {
+					return err
+				}
+
+				// Recounted the same way as a deleted rating, so the aggregates agree
+				// with concurrent rating changes.
+				txMovieRepo := a.movieRepository.WithTx(ctx, tx)
+				for _, movieID := range movieIDs {
+					if err = txMovieRepo.RecalculateRating(ctx, movieID); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+			switch {
+			case err == nil:
+				purged++
+				slg.Logger.Info("purged deleted account", "user_id", userID)
+			case errors.Is(err, repository.ErrRecordNotFound):
+			default:
+				slg.Logger.Error("failed to purge deleted account", "user_id", userID, "error", err)
+			}
+		}
+
+		if purged == 0 || len(userIDs) < purgeBatchSize {
+			return nil
+		}
+	}
+}
+
+func NewAccountPurger(accountRepository repository.AccountRepository, movieRepository repository.MovieRepository, txService transaction.TxService) *AccountPurger {
+	return &AccountPurger{
+		accountRepository: accountRepository,
+		movieRepository:   movieRepository,
+		txService:         txService,
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+)
+
+type RatingService interface {
+	GetRating(ctx context.Context, userID, movieID int64) (*domain.Rating, error)
+	SetRating(ctx context.Context, userID, movieID int64, input *dto.Rating) (*domain.Rating, error)
+	DeleteRating(ctx context.Context, userID, movieID int64) error
+}
+
+type ratingService struct {
+	ratingRepository repository.RatingRepository
+	movieRepository  repository.MovieRepository
+	txService        transaction.TxService
+}
+
+func (r *ratingService) GetRating(ctx context.Context, userID, movieID int64) (*domain.Rating, error) {
+	return r.ratingRepository.GetRating(ctx, userID, movieID)
+}
+
+func (r *ratingService) SetRating(ctx context.Context, userID, movieID int64, input *dto.Rating) (*domain.Rating, error) {
+	v := validator.New()
+
+	rating := &domain.Rating{
+		UserID:  userID,
+		MovieID: movieID,
+		Score:   input.Score,
+	}
+
+	if domain.ValidateRating(v, rating); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	err := r.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return applyRating(ctx, r.ratingRepository.WithTx(ctx, tx), r.movieRepository.WithTx(ctx, tx), rating)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rating, nil
+}
+
+func (r *ratingService) DeleteRating(ctx context.Context, userID, movieID int64) error {
+	return r.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := r.ratingRepository.WithTx(ctx, tx).DeleteRating(ctx, userID, movieID); err != nil {
+			return err
+		}
+
+		return r.movieRepository.WithTx(ctx, tx).RecalculateRating(ctx, movieID)
+	})
+}
+
+// applyRating stores the rating and recounts the movie's aggregate from its ratings, rather
+// than applying a delta that a concurrent change to the same rating could make stale. Both
+// repositories must be bound to the same transaction.
+func applyRating(ctx context.Context, ratingRepo repository.RatingRepository, movieRepo repository.MovieRepository, rating *domain.Rating) error {
+	if err := ratingRepo.UpsertRating(ctx, rating); err != nil {
+		return err
+	}
+
+	return movieRepo.RecalculateRating(ctx, rating.MovieID)
+}
+
+func NewRatingService(ratingRepository repository.RatingRepository, movieRepository repository.MovieRepository, txService transaction.TxService) RatingService {
+	return &ratingService{
+		ratingRepository: ratingRepository,
+		movieRepository:  movieRepository,
+		txService:        txService,
+	}
+}
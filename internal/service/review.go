@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"time"
+)
+
+type ReviewService interface {
+	CreateReview(ctx context.Context, user *domain.User, movieID int64, input *dto.Review) (*domain.Review, error)
+	GetPublishedReviews(ctx context.Context, movieID int64, filters domain.Filters) ([]*domain.Review, domain.Metadata, error)
+	GetReviewsByStatus(ctx context.Context, status string, filters domain.Filters) ([]*domain.Review, domain.Metadata, error)
+	UpdateReview(ctx context.Context, user *domain.User, movieID, reviewID int64, input *dto.UpdateReview) (*domain.Review, error)
+	DeleteReview(ctx context.Context, user *domain.User, movieID, reviewID int64) error
+	ModerateReview(ctx context.Context, moderator *domain.User, reviewID int64, status string, input *dto.ModerateReview) (*domain.Review, error)
+}
+
+type reviewService struct {
+	reviewRepository repository.ReviewRepository
+	movieRepository  repository.MovieRepository
+	txService        transaction.TxService
+}
+
+func (r *reviewService) CreateReview(ctx context.Context, user *domain.User, movieID int64, input *dto.Review) (*domain.Review, error) {
+	v := validator.New()
+
+	review := &domain.Review{
+		MovieID:  movieID,
+		UserID:   user.ID,
+		UserName: user.Name,
+		Title:    input.Title,
+		Body:     input.Body,
+		Status:   domain.ReviewStatusPending,
+	}
+
+	if domain.ValidateReview(v, review); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var createdReview *domain.Review
+	err := r.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		createdReview, err = r.reviewRepository.WithTx(ctx, tx).CreateReview(ctx, review)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createdReview, nil
+}
+
+func (r *reviewService) GetPublishedReviews(ctx context.Context, movieID int64, filters domain.Filters) ([]*domain.Review, domain.Metadata, error) {
+	if _, err := r.movieRepository.GetMovieById(ctx, movieID); err != nil {
+		return nil, domain.Metadata{}, err
+	}
+
+	return r.reviewRepository.GetReviews(ctx, movieID, domain.ReviewStatusPublished, filters)
+}
+
+func (r *reviewService) GetReviewsByStatus(ctx context.Context, status string, filters domain.Filters) ([]*domain.Review, domain.Metadata, error) {
+	return r.reviewRepository.GetReviews(ctx, 0, status, filters)
+}
+
+func (r *reviewService) UpdateReview(ctx context.Context, user *domain.User, movieID, reviewID int64, input *dto.UpdateReview) (*domain.Review, error) {
+	var updatedReview *domain.Review
+
+	err := r.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := r.reviewRepository.WithTx(ctx, tx)
+
+		review, err := r.fetchOwnReview(ctx, txRepo, user, movieID, reviewID)
+		if err != nil {
+			return err
+		}
+
+		if input.Title != nil {
+			review.Title = *input.Title
+		}
+		if input.Body != nil {
+			review.Body = *input.Body
+		}
+
+		v := validator.New()
+		if domain.ValidateReview(v, review); !v.Valid() {
+			return v.GetValidationError()
+		}
+
+		// An edited review has to go through moderation again.
+		review.Status = domain.ReviewStatusPending
+		review.ModerationReason = ""
+		review.ModeratedBy = nil
+		review.ModeratedAt = nil
+
+		updatedReview, err = txRepo.UpdateReview(ctx, review)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedReview, nil
+}
+
+func (r *reviewService) DeleteReview(ctx context.Context, user *domain.User, movieID, reviewID int64) error {
+	return r.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := r.reviewRepository.WithTx(ctx, tx)
+
+		if _, err := r.fetchOwnReview(ctx, txRepo, user, movieID, reviewID); err != nil {
+			return err
+		}
+
+		return txRepo.DeleteReview(ctx, reviewID)
+	})
+}
+
+func (r *reviewService) ModerateReview(ctx context.Context, moderator *domain.User, reviewID int64, status string, input *dto.ModerateReview) (*domain.Review, error) {
+	v := validator.New()
+
+	if domain.ValidateModeration(v, status, input.Reason); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var moderatedReview *domain.Review
+
+	err := r.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := r.reviewRepository.WithTx(ctx, tx)
+
+		review, err := txRepo.GetReviewById(ctx, reviewID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		review.Status = status
+		review.ModerationReason = input.Reason
+		review.ModeratedBy = &moderator.ID
+		review.ModeratedAt = &now
+
+		moderatedReview, err = txRepo.UpdateReview(ctx, review)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return moderatedReview, nil
+}
+
+// fetchOwnReview loads a review of the given movie and makes sure it was written by user.
+func (r *reviewService) fetchOwnReview(ctx context.Context, repo repository.ReviewRepository, user *domain.User, movieID, reviewID int64) (*domain.Review, error) {
+	review, err := repo.GetReviewById(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.MovieID != movieID {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	if review.UserID != user.ID {
+		return nil, ErrNotPermitted
+	}
+
+	return review, nil
+}
+
+func NewReviewService(reviewRepository repository.ReviewRepository, movieRepository repository.MovieRepository, txService transaction.TxService) ReviewService {
+	return &reviewService{
+		reviewRepository: reviewRepository,
+		movieRepository:  movieRepository,
+		txService:        txService,
+	}
+}
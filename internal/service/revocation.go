@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/pkg/jwt"
+	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+	"time"
+)
+
+// SessionRevocations keeps the signer's list of revoked sessions in step with the
+// revoked_sessions table, so that a session signed out on one instance, or before a
+// restart, is rejected by every instance.
+type SessionRevocations struct {
+	tokenRepository repository.TokenRepository
+	signer          *jwt.Signer
+	txService       transaction.TxService
+}
+
+// Load replays every revocation that has not yet expired into the signer.
+func (s *SessionRevocations) Load(ctx context.Context) error {
+	sessions, err := s.tokenRepository.GetRevokedSessions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for sessionID, expiresAt := range sessions {
+		s.signer.RevokeSession(sessionID, expiresAt)
+	}
+
+	return nil
+}
+
+func (s *SessionRevocations) Channel() string {
+	return repository.SessionsRevokedChannel
+}
+
+// Notify applies a revocation made elsewhere. The notification only carries the session id,
+// so it is kept for a full token lifetime, which is at least as long as the one stored.
+func (s *SessionRevocations) Notify(payload string) {
+	s.signer.RevokeSession(payload, time.Now().Add(s.signer.TTL()))
+}
+
+func (s *SessionRevocations) Resync(ctx context.Context) {
+	if err := s.Load(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		slg.Logger.Error("failed to reload revoked sessions", "error", err)
+	}
+}
+
+// Run drops expired revocations from the table and the signer every interval until ctx is
+// cancelled.
+func (s *SessionRevocations) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := s.txService.WithTx(ctx, func(tx *sql.Tx) error {
+			return s.tokenRepository.WithTx(ctx, tx).DeleteExpiredRevokedSessions(ctx)
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			slg.Logger.Error("failed to delete expired session revocations", "error", err)
+		}
+
+		s.signer.Cleanup()
+	}
+}
+
+func NewSessionRevocations(tokenRepository repository.TokenRepository, signer *jwt.Signer, txService transaction.TxService) *SessionRevocations {
+	return &SessionRevocations{
+		tokenRepository: tokenRepository,
+		signer:          signer,
+		txService:       txService,
+	}
+}
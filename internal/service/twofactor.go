@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"github.com/saleh-ghazimoradi/Cinemaniac/pkg/totp"
+	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorService interface {
+	GetStatus(ctx context.Context, user *domain.User) (*domain.TwoFactorStatus, error)
+	Enroll(ctx context.Context, user *domain.User) (*domain.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, user *domain.User, input *dto.TwoFactorCode) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, user *domain.User, input *dto.TwoFactorCode) ([]string, error)
+	Disable(ctx context.Context, user *domain.User, input *dto.DisableTwoFactor) error
+}
+
+type twoFactorService struct {
+	twoFactorRepository repository.TwoFactorRepository
+	userRepository      repository.UserRepository
+	txService           transaction.TxService
+}
+
+func (t *twoFactorService) GetStatus(ctx context.Context, user *domain.User) (*domain.TwoFactorStatus, error) {
+	status := &domain.TwoFactorStatus{}
+
+	twoFactor, err := t.twoFactorRepository.GetTwoFactor(ctx, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return status, nil
+		default:
+			return nil, err
+		}
+	}
+
+	status.Enabled = twoFactor.Confirmed
+
+	if status.Enabled {
+		if status.RecoveryCodesRemaining, err = t.twoFactorRepository.CountRecoveryCodes(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// Enroll starts 2FA enrollment with a fresh secret. Sign-in is unaffected until the user
+// proves they have set up their authenticator by confirming a code.
+func (t *twoFactorService) Enroll(ctx context.Context, user *domain.User) (*domain.TwoFactorEnrollment, error) {
+	account, err := t.userRepository.GetUserById(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor := &domain.TwoFactor{
+		UserID: user.ID,
+		Secret: totp.GenerateSecret(),
+	}
+
+	err = t.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return t.twoFactorRepository.WithTx(ctx, tx).UpsertPending(ctx, twoFactor)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrEditConflict):
+			return nil, ErrTwoFactorEnabled
+		default:
+			return nil, err
+		}
+	}
+
+	return &domain.TwoFactorEnrollment{
+		Secret: twoFactor.Secret,
+		URI:    totp.URI(config.AppConfig.Auth.TOTPIssuer, account.Email, twoFactor.Secret),
+	}, nil
+}
+
+// Confirm enables 2FA and returns the recovery codes, which are only ever shown here.
+func (t *twoFactorService) Confirm(ctx context.Context, user *domain.User, input *dto.TwoFactorCode) ([]string, error) {
+	v := validator.New()
+
+	if domain.ValidateTOTPCode(v, input.Code); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var codes []string
+
+	err := t.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := t.twoFactorRepository.WithTx(ctx, tx)
+
+		twoFactor, err := txRepo.GetTwoFactor(ctx, user.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, repository.ErrRecordNotFound):
+				return ErrTwoFactorNotEnabled
+			default:
+				return err
+			}
+		}
+
+		if twoFactor.Confirmed {
+			return ErrTwoFactorEnabled
+		}
+
+		if err = verifySecondFactor(ctx, txRepo, twoFactor, input.Code); err != nil {
+			return err
+		}
+
+		if err = txRepo.Confirm(ctx, user.ID); err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(ctx, txRepo, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (t *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, user *domain.User, input *dto.TwoFactorCode) ([]string, error) {
+	v := validator.New()
+
+	if domain.ValidateTOTPCode(v, input.Code); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var codes []string
+
+	err := t.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := t.twoFactorRepository.WithTx(ctx, tx)
+
+		twoFactor, err := fetchEnabledTwoFactor(ctx, txRepo, user.ID)
+		if err != nil {
+			return err
+		}
+
+		if err = verifySecondFactor(ctx, txRepo, twoFactor, input.Code); err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(ctx, txRepo, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable turns 2FA off. It asks for both the password and a current code so that a
+// stolen session alone is not enough to weaken the account.
+func (t *twoFactorService) Disable(ctx context.Context, user *domain.User, input *dto.DisableTwoFactor) error {
+	v := validator.New()
+
+	domain.ValidatePasswordPlaintext(v, input.Password)
+	domain.ValidateTwoFactorCode(v, input.Code)
+	if err := v.GetValidationError(); err != nil {
+		return err
+	}
+
+	account, err := t.userRepository.GetUserById(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	match, err := account.Password.Matches(input.Password)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		return ErrInvalidCredentials
+	}
+
+	return t.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txRepo := t.twoFactorRepository.WithTx(ctx, tx)
+
+		twoFactor, err := fetchEnabledTwoFactor(ctx, txRepo, user.ID)
+		if err != nil {
+			return err
+		}
+
+		if err = verifySecondFactor(ctx, txRepo, twoFactor, input.Code); err != nil {
+			return err
+		}
+
+		return txRepo.DeleteTwoFactor(ctx, user.ID)
+	})
+}
+
+func fetchEnabledTwoFactor(ctx context.Context, repo repository.TwoFactorRepository, userID int64) (*domain.TwoFactor, error) {
+	twoFactor, err := repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil, ErrTwoFactorNotEnabled
+		default:
+			return nil, err
+		}
+	}
+
+	if !twoFactor.Confirmed {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	return twoFactor, nil
+}
+
+// verifySecondFactor accepts a TOTP code for a step that has not been used yet, or an
+// unused recovery code, and consumes it.
+func verifySecondFactor(ctx context.Context, repo repository.TwoFactorRepository, twoFactor *domain.TwoFactor, code string) error {
+	if validator.Matches(code, domain.TOTPCodeRX) {
+		step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+
+		if err := repo.UseStep(ctx, twoFactor.UserID, step); err != nil {
+			switch {
+			case errors.Is(err, repository.ErrEditConflict):
+				return ErrInvalidTwoFactorCode
+			default:
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if !twoFactor.Confirmed {
+		return ErrInvalidTwoFactorCode
+	}
+
+	err := repo.UseRecoveryCode(ctx, twoFactor.UserID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return ErrInvalidTwoFactorCode
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, repo repository.TwoFactorRepository, userID int64) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([][]byte, recoveryCodeCount)
+
+	for i := range codes {
+		codes[i] = utils.GenerateRecoveryCode()
+		hashes[i] = utils.HashToken(utils.NormalizeRecoveryCode(codes[i]))
+	}
+
+	if err := repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func NewTwoFactorService(twoFactorRepository repository.TwoFactorRepository, userRepository repository.UserRepository, txService transaction.TxService) TwoFactorService {
+	return &twoFactorService{
+		twoFactorRepository: twoFactorRepository,
+		userRepository:      userRepository,
+		txService:           txService,
+	}
+}
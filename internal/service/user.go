@@ -2,32 +2,64 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/dto"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/repository"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/transaction"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/validator"
+	"github.com/saleh-ghazimoradi/Cinemaniac/pkg/jwt"
 	"github.com/saleh-ghazimoradi/Cinemaniac/pkg/notification"
 	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
 	"github.com/saleh-ghazimoradi/Cinemaniac/utils"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type UserService interface {
 	CreateUser(ctx context.Context, input *dto.User) (*domain.User, error)
 	ActivateUser(ctx context.Context, input *dto.ActivateUserRequest) (*domain.User, error)
-	CreateAuthenticationToken(ctx context.Context, input *dto.Token) (*domain.Token, error)
+	CreateAuthenticationToken(ctx context.Context, input *dto.Token) (*domain.AuthenticationTokens, error)
+	CreateTwoFactorAuthenticationToken(ctx context.Context, input *dto.TwoFactorLogin) (*domain.AuthenticationTokens, error)
+	CreateMagicLinkToken(ctx context.Context, input *dto.EmailTokenRequest) error
+	CreateMagicLinkAuthenticationToken(ctx context.Context, input *dto.MagicLinkLogin) (*domain.AuthenticationTokens, error)
+	RefreshAuthenticationToken(ctx context.Context, input *dto.RefreshTokenRequest) (*domain.AuthenticationTokens, error)
+	RevokeAuthenticationToken(ctx context.Context, tokenPlaintext string) error
+	RevokeAllAuthenticationTokens(ctx context.Context, userID int64) error
+	RevokeUserSessions(ctx context.Context, userID int64) error
+	UnlockUser(ctx context.Context, userID int64) error
+	GetSessions(ctx context.Context, user *domain.User, currentToken string) ([]*domain.Session, error)
+	RevokeSession(ctx context.Context, user *domain.User, id int64) error
+	CreateActivationToken(ctx context.Context, input *dto.EmailTokenRequest) error
+	CreatePasswordResetToken(ctx context.Context, input *dto.EmailTokenRequest) error
+	ResetPassword(ctx context.Context, input *dto.ResetPasswordRequest) error
+	GetProfile(ctx context.Context, user *domain.User) (*domain.User, error)
+	UpdateProfile(ctx context.Context, user *domain.User, input *dto.UpdateProfile) (*domain.User, error)
+	ChangePassword(ctx context.Context, user *domain.User, currentToken string, input *dto.ChangePassword) error
+	RequestEmailChange(ctx context.Context, user *domain.User, input *dto.ChangeEmail) error
+	ConfirmEmailChange(ctx context.Context, input *dto.ConfirmEmailChange) (*domain.User, error)
+	ExportAccount(ctx context.Context, user *domain.User) (*domain.AccountExport, error)
+	DeleteAccount(ctx context.Context, user *domain.User, currentToken string, input *dto.DeleteAccount) (*domain.AccountDeletion, error)
+	CancelAccountDeletion(ctx context.Context, user *domain.User) error
 }
 
 type userService struct {
-	userRepository  repository.UserRepository
-	txService       transaction.TxService
-	notification    notification.Mailer
-	tokenRepository repository.TokenRepository
-	permissions     repository.PermissionRepository
+	userRepository         repository.UserRepository
+	txService              transaction.TxService
+	notification           notification.Mailer
+	tokenRepository        repository.TokenRepository
+	permissions            repository.PermissionRepository
+	signer                 *jwt.Signer
+	twoFactorRepository    repository.TwoFactorRepository
+	loginAttemptRepository repository.LoginAttemptRepository
+	apiKeyRepository       repository.ApiKeyRepository
+	accountRepository      repository.AccountRepository
 }
 
 func (u *userService) CreateUser(ctx context.Context, input *dto.User) (*domain.User, error) {
@@ -60,12 +92,12 @@ func (u *userService) CreateUser(ctx context.Context, input *dto.User) (*domain.
 		}
 
 		txPermissionRepo := u.permissions.WithTx(ctx, tx) // Ensure transaction is used
-		if err := txPermissionRepo.AddForUser(user.ID, "movies:read"); err != nil {
-			slg.Logger.Error("failed to add permissions", "user_id", user.ID, "error", err)
-			return fmt.Errorf("error adding permissions: %w", err)
+		if _, err := txPermissionRepo.AssignRole(ctx, user.ID, config.AppConfig.Auth.DefaultRole); err != nil {
+			slg.Logger.Error("failed to assign default role", "user_id", user.ID, "role", config.AppConfig.Auth.DefaultRole, "error", err)
+			return fmt.Errorf("error assigning default role: %w", err)
 		}
 
-		token = utils.GenerateToken(user.ID, 72*time.Hour, domain.ScopeActivation)
+		token = utils.GenerateToken(user.ID, config.AppConfig.Token.ActivationTTL, domain.ScopeActivation)
 
 		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
 		return txTokenRepo.Insert(ctx, token)
@@ -123,7 +155,7 @@ func (u *userService) ActivateUser(ctx context.Context, input *dto.ActivateUserR
 	return user, nil
 }
 
-func (u *userService) CreateAuthenticationToken(ctx context.Context, input *dto.Token) (*domain.Token, error) {
+func (u *userService) CreateAuthenticationToken(ctx context.Context, input *dto.Token) (*domain.AuthenticationTokens, error) {
 	v := validator.New()
 
 	domain.ValidateEmail(v, input.Email)
@@ -132,10 +164,17 @@ func (u *userService) CreateAuthenticationToken(ctx context.Context, input *dto.
 		return nil, err
 	}
 
+	if err := u.checkLoginAllowed(ctx, input.Email, input.IP); err != nil {
+		return nil, err
+	}
+
 	user, err := u.userRepository.GetUserByEmail(ctx, input.Email)
 	if err != nil {
 		switch {
 		case errors.Is(err, repository.ErrRecordNotFound):
+			if err = u.recordLoginFailure(ctx, nil, input.Email, input.IP); err != nil {
+				return nil, err
+			}
 			return nil, errors.New("invalid credentials")
 		default:
 			return nil, err
@@ -148,28 +187,606 @@ func (u *userService) CreateAuthenticationToken(ctx context.Context, input *dto.
 	}
 
 	if !match {
+		if err = u.recordLoginFailure(ctx, user, input.Email, input.IP); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid credentials")
 	}
 
-	token := utils.GenerateToken(user.ID, 24*time.Hour, domain.ScopeAuthentication)
+	return u.completeSignIn(ctx, user, input.IP, input.UserAgent)
+}
+
+// CreateMagicLinkToken emails a single-use sign-in link to the address if it belongs to an
+// activated account. Like CreatePasswordResetToken it reports nothing about the address.
+func (u *userService) CreateMagicLinkToken(ctx context.Context, input *dto.EmailTokenRequest) error {
+	v := validator.New()
+
+	if domain.ValidateEmail(v, input.Email); !v.Valid() {
+		return v.GetValidationError()
+	}
+
+	user, err := u.userRepository.GetUserByEmail(ctx, input.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil
+		default:
+			return err
+		}
+	}
+
+	if !user.Activated {
+		return nil
+	}
+
+	token := utils.GenerateToken(user.ID, config.AppConfig.Token.LoginTTL, domain.ScopeLogin)
+
+	err = u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		if err := txTokenRepo.DeleteAllForUser(ctx, domain.ScopeLogin, user.ID); err != nil {
+			return err
+		}
+
+		return txTokenRepo.Insert(ctx, token)
+	})
+	if err != nil {
+		return err
+	}
+
+	background(func() {
+		data := map[string]any{
+			"loginURL":   config.AppConfig.Auth.MagicLinkURL + "?token=" + url.QueryEscape(token.Plaintext),
+			"loginToken": token.Plaintext,
+			"expiresIn":  config.AppConfig.Token.LoginTTL.String(),
+		}
+
+		err := u.notification.Send(user.Email, "token_magic_link.tmpl", data)
+		if err != nil {
+			slg.Logger.Error(err.Error())
+		}
+	})
+
+	return nil
+}
+
+// CreateMagicLinkAuthenticationToken signs in with a token from a magic link. The link
+// stands in for the password only; lockouts and 2FA apply as usual.
+func (u *userService) CreateMagicLinkAuthenticationToken(ctx context.Context, input *dto.MagicLinkLogin) (*domain.AuthenticationTokens, error) {
+	v := validator.New()
+
+	if domain.ValidateTokenPlaintext(v, input.TokenPlaintext); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	user, err := u.userRepository.GetForToken(ctx, domain.ScopeLogin, input.TokenPlaintext)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil, ErrInvalidToken
+		default:
+			return nil, err
+		}
+	}
+
+	if err = u.checkLoginAllowed(ctx, user.Email, input.IP); err != nil {
+		return nil, err
+	}
+
+	err = u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return u.tokenRepository.WithTx(ctx, tx).Consume(ctx, domain.ScopeLogin, input.TokenPlaintext)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil, ErrInvalidToken
+		default:
+			return nil, err
+		}
+	}
+
+	return u.completeSignIn(ctx, user, input.IP, input.UserAgent)
+}
+
+// completeSignIn finishes a sign-in once the first factor has been checked: it asks for a
+// second factor if the user has 2FA enabled and otherwise issues a token pair.
+func (u *userService) completeSignIn(ctx context.Context, user *domain.User, ip, userAgent string) (*domain.AuthenticationTokens, error) {
+	twoFactor, err := u.twoFactorRepository.GetTwoFactor(ctx, user.ID)
+	if err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if twoFactor != nil && twoFactor.Confirmed {
+		return u.createTwoFactorPendingToken(ctx, user)
+	}
+
+	if err = u.resetLoginFailures(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	var tokens *domain.AuthenticationTokens
+
+	err = u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		tokens, err = u.issueAuthenticationTokens(ctx, u.tokenRepository.WithTx(ctx, tx), user, rand.Text(), ip, userAgent)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// CreateTwoFactorAuthenticationToken completes a sign-in that was held back for a second
+// factor by exchanging the 2fa-pending token and a valid code for a token pair.
+func (u *userService) CreateTwoFactorAuthenticationToken(ctx context.Context, input *dto.TwoFactorLogin) (*domain.AuthenticationTokens, error) {
+	v := validator.New()
+
+	domain.ValidateTokenPlaintext(v, input.TokenPlaintext)
+	domain.ValidateTwoFactorCode(v, input.Code)
+	if err := v.GetValidationError(); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepository.GetForToken(ctx, domain.ScopeTwoFactorPending, input.TokenPlaintext)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil, ErrInvalidToken
+		default:
+			return nil, err
+		}
+	}
+
+	// Wrong codes count towards the same lockout as wrong passwords.
+	if err = u.checkLoginAllowed(ctx, user.Email, input.IP); err != nil {
+		return nil, err
+	}
+
+	var tokens *domain.AuthenticationTokens
+
+	err = u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+		txTwoFactorRepo := u.twoFactorRepository.WithTx(ctx, tx)
+
+		twoFactor, err := fetchEnabledTwoFactor(ctx, txTwoFactorRepo, user.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrTwoFactorNotEnabled):
+				return ErrInvalidToken
+			default:
+				return err
+			}
+		}
+
+		if err = verifySecondFactor(ctx, txTwoFactorRepo, twoFactor, input.Code); err != nil {
+			return err
+		}
+
+		if err = txTokenRepo.DeleteAllForUser(ctx, domain.ScopeTwoFactorPending, user.ID); err != nil {
+			return err
+		}
+
+		tokens, err = u.issueAuthenticationTokens(ctx, txTokenRepo, user, rand.Text(), input.IP, input.UserAgent)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if recordErr := u.recordLoginFailure(ctx, user, user.Email, input.IP); recordErr != nil {
+				return nil, recordErr
+			}
+		}
+		return nil, err
+	}
+
+	if err = u.resetLoginFailures(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// UnlockUser clears the failed sign-in record of an account, lifting any lock or backoff.
+func (u *userService) UnlockUser(ctx context.Context, userID int64) error {
+	user, err := u.userRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return u.resetLoginFailures(ctx, user.Email)
+}
+
+// RefreshAuthenticationToken exchanges a refresh token for a new token pair in the same
+// family. A refresh token can only be exchanged once: presenting one that has already
+// been rotated means it has leaked, so the whole family is revoked.
+func (u *userService) RefreshAuthenticationToken(ctx context.Context, input *dto.RefreshTokenRequest) (*domain.AuthenticationTokens, error) {
+	v := validator.New()
+
+	if domain.ValidateTokenPlaintext(v, input.TokenPlaintext); !v.Valid() {
+		return nil, v.GetValidationError()
+	}
+
+	var tokens *domain.AuthenticationTokens
+	reused := false
+
+	err := u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		token, err := txTokenRepo.GetForPlaintext(ctx, domain.ScopeRefresh, input.TokenPlaintext)
+		if err != nil {
+			return err
+		}
+
+		if token.Rotated {
+			reused = true
+			if err = u.revokeSignedSessions(ctx, txTokenRepo, token.Family); err != nil {
+				return err
+			}
+			return txTokenRepo.DeleteFamily(ctx, token.Family)
+		}
+
+		if time.Now().After(token.Expiry) {
+			return ErrInvalidToken
+		}
+
+		if err = txTokenRepo.MarkRotated(ctx, token); err != nil {
+			return err
+		}
+
+		if err = txTokenRepo.DeleteFamilyScope(ctx, token.Family, domain.ScopeAuthentication); err != nil {
+			return err
+		}
+
+		user, err := u.userRepository.WithTx(ctx, tx).GetUserById(ctx, token.UserID)
+		if err != nil {
+			return err
+		}
+
+		tokens, err = u.issueAuthenticationTokens(ctx, txTokenRepo, user, token.Family, input.IP, input.UserAgent)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil, ErrInvalidToken
+		default:
+			return nil, err
+		}
+	}
+
+	if reused {
+		return nil, ErrInvalidToken
+	}
+
+	return tokens, nil
+}
+
+// RevokeAuthenticationToken signs out the session the token belongs to, including its
+// refresh token.
+func (u *userService) RevokeAuthenticationToken(ctx context.Context, tokenPlaintext string) error {
+	family, err := u.sessionFamily(ctx, tokenPlaintext)
+	if err != nil {
+		return err
+	}
+
+	return u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		if err := txTokenRepo.DeleteFamily(ctx, family); err != nil {
+			return err
+		}
+
+		return u.revokeSignedSessions(ctx, txTokenRepo, family)
+	})
+}
+
+func (u *userService) RevokeAllAuthenticationTokens(ctx context.Context, userID int64) error {
+	return u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		return u.revokeAllSessions(ctx, u.tokenRepository.WithTx(ctx, tx), userID)
+	})
+}
+
+// RevokeUserSessions signs another user out everywhere. It is the administrative
+// counterpart of RevokeAllAuthenticationTokens and fails for unknown users.
+func (u *userService) RevokeUserSessions(ctx context.Context, userID int64) error {
+	if _, err := u.userRepository.GetUserById(ctx, userID); err != nil {
+		return err
+	}
+
+	return u.RevokeAllAuthenticationTokens(ctx, userID)
+}
+
+func (u *userService) GetSessions(ctx context.Context, user *domain.User, currentToken string) ([]*domain.Session, error) {
+	family, err := u.sessionFamily(ctx, currentToken)
+	if err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return u.tokenRepository.GetSessionsForUser(ctx, user.ID, family)
+}
+
+func (u *userService) RevokeSession(ctx context.Context, user *domain.User, id int64) error {
+	return u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		family, err := txTokenRepo.DeleteSession(ctx, user.ID, id)
+		if err != nil {
+			return err
+		}
+
+		return u.revokeSignedSessions(ctx, txTokenRepo, family)
+	})
+}
+
+// CreateActivationToken replaces any outstanding activation token of an unactivated account
+// and emails the new one. Like CreatePasswordResetToken it reports nothing about whether
+// the address exists.
+func (u *userService) CreateActivationToken(ctx context.Context, input *dto.EmailTokenRequest) error {
+	v := validator.New()
+
+	if domain.ValidateEmail(v, input.Email); !v.Valid() {
+		return v.GetValidationError()
+	}
+
+	user, err := u.userRepository.GetUserByEmail(ctx, input.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil
+		default:
+			return err
+		}
+	}
+
+	if user.Activated {
+		return nil
+	}
+
+	token := utils.GenerateToken(user.ID, config.AppConfig.Token.ActivationTTL, domain.ScopeActivation)
 
 	err = u.txService.WithTx(ctx, func(tx *sql.Tx) error {
 		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		if err := txTokenRepo.DeleteAllForUser(ctx, domain.ScopeActivation, user.ID); err != nil {
+			return err
+		}
+
+		return txTokenRepo.Insert(ctx, token)
+	})
+	if err != nil {
+		return err
+	}
+
+	background(func() {
+		data := map[string]any{
+			"activationToken": token.Plaintext,
+		}
+
+		err := u.notification.Send(user.Email, "token_activation.tmpl", data)
+		if err != nil {
+			slg.Logger.Error(err.Error())
+		}
+	})
+
+	return nil
+}
+
+// CreatePasswordResetToken emails a password reset token to the address if it belongs to
+// an activated account. Unknown addresses are not reported, so the endpoint cannot be used
+// to find out who has an account.
+func (u *userService) CreatePasswordResetToken(ctx context.Context, input *dto.EmailTokenRequest) error {
+	v := validator.New()
+
+	if domain.ValidateEmail(v, input.Email); !v.Valid() {
+		return v.GetValidationError()
+	}
+
+	user, err := u.userRepository.GetUserByEmail(ctx, input.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil
+		default:
+			return err
+		}
+	}
+
+	if !user.Activated {
+		return nil
+	}
+
+	token := utils.GenerateToken(user.ID, config.AppConfig.Token.PasswordResetTTL, domain.ScopePasswordReset)
+
+	err = u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		if err := txTokenRepo.DeleteAllForUser(ctx, domain.ScopePasswordReset, user.ID); err != nil {
+			return err
+		}
+
+		return txTokenRepo.Insert(ctx, token)
+	})
+	if err != nil {
+		return err
+	}
+
+	background(func() {
+		data := map[string]any{
+			"passwordResetToken": token.Plaintext,
+		}
+
+		err := u.notification.Send(user.Email, "token_password_reset.tmpl", data)
+		if err != nil {
+			slg.Logger.Error(err.Error())
+		}
+	})
+
+	return nil
+}
+
+func (u *userService) ResetPassword(ctx context.Context, input *dto.ResetPasswordRequest) error {
+	v := validator.New()
+
+	domain.ValidatePasswordPlaintext(v, input.Password)
+	domain.ValidateTokenPlaintext(v, input.TokenPlaintext)
+	if err := v.GetValidationError(); err != nil {
+		return err
+	}
+
+	user, err := u.userRepository.GetForToken(ctx, domain.ScopePasswordReset, input.TokenPlaintext)
+	if err != nil {
+		return err
+	}
+
+	if err = user.Password.Set(input.Password); err != nil {
+		return err
+	}
+
+	return u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txUserRepo := u.userRepository.WithTx(ctx, tx)
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		if err := txUserRepo.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+
+		if err := txTokenRepo.DeleteAllForUser(ctx, domain.ScopePasswordReset, user.ID); err != nil {
+			return err
+		}
+
+		return u.revokeAllSessions(ctx, txTokenRepo, user.ID)
+	})
+}
+
+func (u *userService) createTwoFactorPendingToken(ctx context.Context, user *domain.User) (*domain.AuthenticationTokens, error) {
+	token := utils.GenerateToken(user.ID, config.AppConfig.Token.TwoFactorTTL, domain.ScopeTwoFactorPending)
+	token.Family = rand.Text()
+
+	err := u.txService.WithTx(ctx, func(tx *sql.Tx) error {
+		txTokenRepo := u.tokenRepository.WithTx(ctx, tx)
+
+		if err := txTokenRepo.DeleteAllForUser(ctx, domain.ScopeTwoFactorPending, user.ID); err != nil {
+			return err
+		}
+
 		return txTokenRepo.Insert(ctx, token)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return token, nil
+	return &domain.AuthenticationTokens{TwoFactorPending: token}, nil
+}
+
+// issueAuthenticationTokens stores a new authentication and refresh token pair belonging
+// to the given token family. In signed mode the authentication token is a signed token
+// carrying the user's permissions and only the refresh token is stored. Those permissions
+// are a snapshot: a grant or revoke reaches the session when it next refreshes, at most
+// AccessTokenTTL later, which LoadConfig keeps short.
+func (u *userService) issueAuthenticationTokens(ctx context.Context, tokenRepo repository.TokenRepository, user *domain.User, family, ip, userAgent string) (*domain.AuthenticationTokens, error) {
+	tokens := &domain.AuthenticationTokens{
+		Refresh: utils.GenerateToken(user.ID, config.AppConfig.Token.RefreshTTL, domain.ScopeRefresh),
+	}
+
+	stored := []*domain.Token{tokens.Refresh}
+
+	if u.signer != nil {
+		permissions, err := u.permissions.GetAllForUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		claims := &jwt.Claims{
+			Subject:     strconv.FormatInt(user.ID, 10),
+			Name:        user.Name,
+			Activated:   user.Activated,
+			Permissions: *permissions,
+			SessionID:   family,
+		}
+
+		plaintext, err := u.signer.Sign(claims)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens.Authentication = &domain.Token{
+			Plaintext: plaintext,
+			UserID:    user.ID,
+			Expiry:    time.Unix(claims.ExpiresAt, 0),
+			Scope:     domain.ScopeAuthentication,
+		}
+	} else {
+		tokens.Authentication = utils.GenerateToken(user.ID, config.AppConfig.Token.AuthenticationTTL, domain.ScopeAuthentication)
+		stored = append(stored, tokens.Authentication)
+	}
+
+	for _, token := range stored {
+		token.Family = family
+		token.IP = ip
+		token.UserAgent = userAgent
+
+		if err := tokenRepo.Insert(ctx, token); err != nil {
+			return nil, err
+		}
+	}
+
+	return tokens, nil
+}
+
+func (u *userService) revokeAllSessions(ctx context.Context, tokenRepo repository.TokenRepository, userID int64) error {
+	families, err := tokenRepo.GetFamiliesForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = tokenRepo.DeleteAllForUser(ctx, domain.ScopeRefresh, userID); err != nil {
+		return err
+	}
+
+	if err = tokenRepo.DeleteAllForUser(ctx, domain.ScopeAuthentication, userID); err != nil {
+		return err
+	}
+
+	return u.revokeSignedSessions(ctx, tokenRepo, families...)
+}
+
+// sessionFamily finds the token family an authentication token was issued in.
+func (u *userService) sessionFamily(ctx context.Context, tokenPlaintext string) (string, error) {
+	if u.signer != nil && strings.Contains(tokenPlaintext, ".") {
+		claims, err := u.signer.Verify(tokenPlaintext)
+		if err != nil {
+			return "", repository.ErrRecordNotFound
+		}
+		return claims.SessionID, nil
+	}
+
+	return u.tokenRepository.GetFamilyForToken(ctx, domain.ScopeAuthentication, tokenPlaintext)
+}
+
+// revokeSignedSessions stops signed tokens of the given families from being accepted
+// before they expire. The revocation is stored with tokenRepo's transaction and reaches
+// every instance, this one included, through SessionRevocations once it commits. Opaque
+// tokens need nothing beyond deleting their rows.
+func (u *userService) revokeSignedSessions(ctx context.Context, tokenRepo repository.TokenRepository, families ...string) error {
+	if u.signer == nil || len(families) == 0 {
+		return nil
+	}
+
+	return tokenRepo.RevokeSessions(ctx, families, time.Now().Add(u.signer.TTL()))
 }
 
-func NewUserService(userRepository repository.UserRepository, txService transaction.TxService, notification notification.Mailer, tokenRepository repository.TokenRepository, permissions repository.PermissionRepository) UserService {
+func NewUserService(userRepository repository.UserRepository, txService transaction.TxService, notification notification.Mailer, tokenRepository repository.TokenRepository, permissions repository.PermissionRepository, signer *jwt.Signer, twoFactorRepository repository.TwoFactorRepository, loginAttemptRepository repository.LoginAttemptRepository, apiKeyRepository repository.ApiKeyRepository, accountRepository repository.AccountRepository) UserService {
 	return &userService{
-		userRepository:  userRepository,
-		txService:       txService,
-		notification:    notification,
-		tokenRepository: tokenRepository,
-		permissions:     permissions,
+		userRepository:         userRepository,
+		txService:              txService,
+		notification:           notification,
+		tokenRepository:        tokenRepository,
+		permissions:            permissions,
+		signer:                 signer,
+		twoFactorRepository:    twoFactorRepository,
+		loginAttemptRepository: loginAttemptRepository,
+		apiKeyRepository:       apiKeyRepository,
+		accountRepository:      accountRepository,
 	}
 }
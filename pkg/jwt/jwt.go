@@ -0,0 +1,179 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+type Claims struct {
+	Subject     string   `json:"sub"`
+	Name        string   `json:"name"`
+	Activated   bool     `json:"act"`
+	Permissions []string `json:"perms"`
+	SessionID   string   `json:"sid"`
+	ID          string   `json:"jti"`
+	IssuedAt    int64    `json:"iat"`
+	ExpiresAt   int64    `json:"exp"`
+}
+
+// Signer issues and verifies HS256 tokens. Tokens are signed with the active key and
+// verified with whichever configured key their kid header names, so keys can be rotated
+// by adding a new key, making it active, and removing the old one once its tokens expire.
+//
+// Verify rejects tokens of sessions passed to RevokeSession. The signer only keeps that list
+// in memory; callers are responsible for persisting revocations and replaying them into
+// every instance. Expired entries are dropped by Cleanup.
+type Signer struct {
+	keys        map[string][]byte
+	activeKeyID string
+	ttl         time.Duration
+
+	mu              sync.Mutex
+	revokedSessions map[string]time.Time
+}
+
+// NewSigner builds a Signer from keys in the form "kid:secret".
+func NewSigner(keys []string, activeKeyID string, ttl time.Duration) (*Signer, error) {
+	s := &Signer{
+		keys:            make(map[string][]byte, len(keys)),
+		activeKeyID:     activeKeyID,
+		ttl:             ttl,
+		revokedSessions: make(map[string]time.Time),
+	}
+
+	for _, key := range keys {
+		kid, secret, found := strings.Cut(key, ":")
+		if !found || kid == "" || len(secret) < 32 {
+			return nil, fmt.Errorf("signing key %q must be in the form kid:secret with a secret of at least 32 bytes", kid)
+		}
+		s.keys[kid] = []byte(secret)
+	}
+
+	if _, ok := s.keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active signing key %q is not configured", activeKeyID)
+	}
+
+	return s, nil
+}
+
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+// Sign fills in the registered time and id claims and returns the signed token.
+func (s *Signer) Sign(claims *Claims) (string, error) {
+	now := time.Now()
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(s.ttl).Unix()
+	claims.ID = rand.Text()
+
+	headerJSON, err := json.Marshal(header{Algorithm: "HS256", Type: "JWT", KeyID: s.activeKeyID})
+	if err != nil {
+		return "", err
+	}
+
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(s.keys[s.activeKeyID], unsigned)), nil
+}
+
+func (s *Signer) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var h header
+	if err = json.Unmarshal(headerJSON, &h); err != nil || h.Algorithm != "HS256" {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := s.keys[h.KeyID]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, sign(key, parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidToken
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err = json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt || s.isRevoked(claims.SessionID) {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+// RevokeSession rejects every token of the session until the given time, by which any
+// token issued before the revocation has expired.
+func (s *Signer) RevokeSession(sessionID string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if until.After(s.revokedSessions[sessionID]) {
+		s.revokedSessions[sessionID] = until
+	}
+}
+
+func (s *Signer) isRevoked(sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, found := s.revokedSessions[sessionID]
+	return found
+}
+
+// Cleanup forgets revocations whose tokens have all expired.
+func (s *Signer) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sessionID, until := range s.revokedSessions {
+		if time.Now().After(until) {
+			delete(s.revokedSessions, sessionID)
+		}
+	}
+}
+
+func sign(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,105 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key from RFC 4226 and RFC 6238, "12345678901234567890", in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 Appendix D.
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range want {
+		if got := hotp([]byte("12345678901234567890"), int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %q; want %q", counter, got, code)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	// RFC 6238 Appendix B, SHA-1, truncated to the last six digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatalf("Code returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code(%d) = %q; want %q", tt.unix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not*base32!", time.Unix(59, 0)); err == nil {
+		t.Error("Code accepted an invalid base32 secret")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	issued := time.Unix(1111111111, 0)
+	step := Step(issued)
+
+	code, err := Code(rfcSecret, issued)
+	if err != nil {
+		t.Fatalf("Code returned error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		at       time.Time
+		wantStep int64
+		wantOK   bool
+	}{
+		{"same step", rfcSecret, code, issued, step, true},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code, issued, step, true},
+		{"one step later", rfcSecret, code, issued.Add(period * time.Second), step, true},
+		{"one step earlier", rfcSecret, code, issued.Add(-period * time.Second), step, true},
+		{"two steps later", rfcSecret, code, issued.Add(2 * period * time.Second), 0, false},
+		{"two steps earlier", rfcSecret, code, issued.Add(-2 * period * time.Second), 0, false},
+		{"wrong code", rfcSecret, "000000", issued, 0, false},
+		{"too short", rfcSecret, code[:5], issued, 0, false},
+		{"too long", rfcSecret, code + "0", issued, 0, false},
+		{"empty code", rfcSecret, "", issued, 0, false},
+		{"invalid secret", "not*base32!", code, issued, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := Validate(tt.secret, tt.code, tt.at)
+			if gotStep != tt.wantStep || gotOK != tt.wantOK {
+				t.Errorf("Validate(%q, %q, %d) = %d, %t; want %d, %t", tt.secret, tt.code, tt.at.Unix(), gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret := GenerateSecret()
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("GenerateSecret returned invalid base32 %q: %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Errorf("GenerateSecret returned a %d-byte key; want 20", len(key))
+	}
+}
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS people (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL,
+    biography text NOT NULL DEFAULT '',
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS credits (
+    id bigserial PRIMARY KEY,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    person_id bigint NOT NULL REFERENCES people ON DELETE CASCADE,
+    role text NOT NULL,
+    character_name text NOT NULL DEFAULT '',
+    billing_order integer NOT NULL DEFAULT 0
+);
+
+ALTER TABLE credits ADD CONSTRAINT credits_role_check CHECK (role IN ('actor', 'director', 'writer', 'producer', 'composer', 'cinematographer', 'editor'));
+
+CREATE INDEX IF NOT EXISTS people_name_idx ON people USING GIN (to_tsvector('simple', name));
+CREATE INDEX IF NOT EXISTS credits_movie_id_idx ON credits (movie_id);
+CREATE INDEX IF NOT EXISTS credits_person_id_idx ON credits (person_id);
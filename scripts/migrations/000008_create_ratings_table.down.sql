@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE code = 'ratings:write';
+DROP TABLE IF EXISTS ratings;
+ALTER TABLE movies DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE movies DROP COLUMN IF EXISTS rating_sum;
\ No newline at end of file
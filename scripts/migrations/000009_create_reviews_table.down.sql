@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE code = 'reviews:moderate';
+DROP TABLE IF EXISTS reviews;
\ No newline at end of file
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS reviews (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    title text NOT NULL,
+    body text NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    moderation_reason text NOT NULL DEFAULT '',
+    moderated_by bigint REFERENCES users ON DELETE SET NULL,
+    moderated_at timestamp(0) with time zone,
+    version integer NOT NULL DEFAULT 1,
+    CONSTRAINT reviews_movie_id_user_id_key UNIQUE (movie_id, user_id)
+);
+
+ALTER TABLE reviews ADD CONSTRAINT reviews_status_check CHECK (status IN ('pending', 'published', 'rejected'));
+
+CREATE INDEX IF NOT EXISTS reviews_movie_id_status_idx ON reviews (movie_id, status);
+CREATE INDEX IF NOT EXISTS reviews_status_idx ON reviews (status);
+
+INSERT INTO permissions (code)
+VALUES
+    ('reviews:moderate');
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS watch_history;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS watch_history (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    watched_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS watch_history_user_id_watched_at_idx ON watch_history (user_id, watched_at);
+CREATE INDEX IF NOT EXISTS watch_history_movie_id_idx ON watch_history (movie_id);
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS tokens_family_idx;
+DELETE FROM tokens WHERE scope = 'refresh';
+ALTER TABLE tokens DROP COLUMN IF EXISTS rotated;
+ALTER TABLE tokens DROP COLUMN IF EXISTS family;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS family text NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS rotated boolean NOT NULL DEFAULT false;
+UPDATE tokens SET family = encode(hash, 'hex') WHERE family = '';
+CREATE INDEX IF NOT EXISTS tokens_family_idx ON tokens (family);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS payload text NOT NULL DEFAULT '';
\ No newline at end of file
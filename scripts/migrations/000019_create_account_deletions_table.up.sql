@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS account_deletions (
+    user_id bigint PRIMARY KEY REFERENCES users ON DELETE CASCADE,
+    requested_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    purge_after timestamp(0) with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS account_deletions_purge_after_idx ON account_deletions (purge_after);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS permission_audit;
+DELETE FROM permissions WHERE code = 'permissions:admin';
+ALTER TABLE permissions DROP CONSTRAINT IF EXISTS permissions_code_key;
\ No newline at end of file
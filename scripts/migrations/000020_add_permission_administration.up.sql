@@ -0,0 +1,18 @@
+ALTER TABLE permissions ADD CONSTRAINT permissions_code_key UNIQUE (code);
+
+INSERT INTO permissions (code)
+VALUES
+    ('permissions:admin');
+
+CREATE TABLE IF NOT EXISTS permission_audit (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    actor_id bigint REFERENCES users ON DELETE SET NULL,
+    action text NOT NULL,
+    code text NOT NULL,
+    user_id bigint REFERENCES users ON DELETE SET NULL
+);
+
+ALTER TABLE permission_audit ADD CONSTRAINT permission_audit_action_check CHECK (action IN ('create', 'grant', 'revoke'));
+
+CREATE INDEX IF NOT EXISTS permission_audit_user_id_idx ON permission_audit (user_id);
\ No newline at end of file
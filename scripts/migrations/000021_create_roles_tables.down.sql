@@ -0,0 +1,7 @@
+DELETE FROM permission_audit WHERE action IN ('create_role', 'assign', 'unassign') OR role <> '';
+ALTER TABLE permission_audit DROP CONSTRAINT IF EXISTS permission_audit_action_check;
+ALTER TABLE permission_audit ADD CONSTRAINT permission_audit_action_check CHECK (action IN ('create', 'grant', 'revoke'));
+ALTER TABLE permission_audit DROP COLUMN IF EXISTS role;
+DROP TABLE IF EXISTS users_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id bigserial PRIMARY KEY,
+    name text UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id bigint NOT NULL REFERENCES roles ON DELETE CASCADE,
+    permission_id bigint NOT NULL REFERENCES permissions ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS users_roles (
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    role_id bigint NOT NULL REFERENCES roles ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role_id)
+);
+
+INSERT INTO roles (name)
+VALUES
+    ('member');
+
+INSERT INTO role_permissions
+SELECT roles.id, permissions.id FROM roles, permissions WHERE roles.name = 'member' AND permissions.code IN ('movies:read', 'ratings:write');
+
+ALTER TABLE permission_audit ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT '';
+ALTER TABLE permission_audit DROP CONSTRAINT IF EXISTS permission_audit_action_check;
+ALTER TABLE permission_audit ADD CONSTRAINT permission_audit_action_check CHECK (action IN ('create', 'grant', 'revoke', 'create_role', 'assign', 'unassign'));
\ No newline at end of file
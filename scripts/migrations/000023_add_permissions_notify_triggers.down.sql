@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS role_permissions_notify ON role_permissions;
+DROP TRIGGER IF EXISTS users_roles_notify ON users_roles;
+DROP TRIGGER IF EXISTS users_permissions_notify ON users_permissions;
+DROP FUNCTION IF EXISTS notify_permissions_changed();
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS revoked_sessions_notify ON revoked_sessions;
+DROP FUNCTION IF EXISTS notify_session_revoked();
+DROP TABLE IF EXISTS revoked_sessions;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS revoked_sessions (
+    session_id text PRIMARY KEY,
+    expires_at timestamp(0) with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS revoked_sessions_expires_at_idx ON revoked_sessions (expires_at);
+
+CREATE OR REPLACE FUNCTION notify_session_revoked() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('sessions_revoked', NEW.session_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER revoked_sessions_notify
+AFTER INSERT OR UPDATE ON revoked_sessions
+FOR EACH ROW EXECUTE FUNCTION notify_session_revoked();
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM permissions WHERE code IN ('lists:read', 'lists:write', 'history:read', 'history:write', 'reviews:write', 'ratings:read');
\ No newline at end of file
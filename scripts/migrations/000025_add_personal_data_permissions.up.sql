@@ -0,0 +1,21 @@
+INSERT INTO permissions (code)
+VALUES
+    ('lists:read'),
+    ('lists:write'),
+    ('history:read'),
+    ('history:write'),
+    ('reviews:write'),
+    ('ratings:read')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions
+SELECT roles.id, permissions.id FROM roles, permissions WHERE roles.name = 'member' AND permissions.code IN ('lists:write', 'history:write', 'reviews:write')
+ON CONFLICT DO NOTHING;
+
+-- Accounts from before roles existed hold their permissions directly; keep their access
+-- to lists, history and reviews.
+INSERT INTO users_permissions
+SELECT users.id, permissions.id FROM users, permissions
+WHERE permissions.code IN ('lists:write', 'history:write', 'reviews:write')
+AND NOT EXISTS (SELECT 1 FROM users_roles WHERE users_roles.user_id = users.id)
+ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -4,9 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/saleh-ghazimoradi/Cinemaniac/config"
 	"github.com/saleh-ghazimoradi/Cinemaniac/slg"
+	"time"
 )
 
 func postURI() string {
@@ -38,3 +39,13 @@ func DBConnection() (*sql.DB, error) {
 
 	return db, nil
 }
+
+// DBListener opens a dedicated connection for LISTEN, which pq.Listener keeps open and
+// re-establishes after failures.
+func DBListener() *pq.Listener {
+	return pq.NewListener(postURI(), 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			slg.Logger.Error(err.Error())
+		}
+	})
+}
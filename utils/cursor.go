@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/saleh-ghazimoradi/Cinemaniac/config"
+	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrCursorSecret is returned when cursor pagination is used without a secret strong enough
+// to stop clients forging cursors. Only the cursor mode needs it, so it is not checked at
+// startup.
+var ErrCursorSecret = errors.New("CURSOR_SECRET must be set to at least 32 bytes to use cursor pagination")
+
+func EncodeCursor(cursor *domain.Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	signature, err := signCursor(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	return encoded + "." + signature, nil
+}
+
+func DecodeCursor(value string) (*domain.Cursor, error) {
+	encoded, signature, found := strings.Cut(value, ".")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	expected, err := signCursor(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor domain.Cursor
+	if err = json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func signCursor(encoded string) (string, error) {
+	secret := config.AppConfig.Pagination.CursorSecret
+	if len(secret) < 32 {
+		return "", ErrCursorSecret
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"crypto/rand"
+	"strings"
+	"unicode"
+)
+
+// GenerateSlug turns name into a URL-safe slug with a random suffix, so that lists with
+// the same name still get distinct, hard to guess slugs.
+func GenerateSlug(name string) string {
+	var b strings.Builder
+
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 50 {
+			break
+		}
+	}
+
+	base := strings.Trim(b.String(), "-")
+	suffix := strings.ToLower(rand.Text()[:8])
+	if base == "" {
+		return suffix
+	}
+
+	return base + "-" + suffix
+}
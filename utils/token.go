@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"github.com/saleh-ghazimoradi/Cinemaniac/internal/domain"
+	"strings"
 	"time"
 )
 
@@ -13,8 +14,33 @@ func GenerateToken(userId int64, ttl time.Duration, scope string) *domain.Token
 	token.UserID = userId
 	token.Expiry = time.Now().Add(ttl)
 	token.Scope = scope
-	hash := sha256.Sum256([]byte(token.Plaintext))
-	token.Hash = hash[:]
+	token.Hash = HashToken(token.Plaintext)
 
 	return &token
 }
+
+// GenerateApiKey creates a key with the same entropy and hashing as GenerateToken.
+func GenerateApiKey(userId int64) *domain.ApiKey {
+	var key domain.ApiKey
+	key.Plaintext = rand.Text()
+	key.UserID = userId
+	key.Hash = HashToken(key.Plaintext)
+
+	return &key
+}
+
+func HashToken(plaintext string) []byte {
+	hash := sha256.Sum256([]byte(plaintext))
+	return hash[:]
+}
+
+// GenerateRecoveryCode returns a single-use code in the form xxxxx-xxxxx.
+func GenerateRecoveryCode() string {
+	code := strings.ToLower(rand.Text()[:10])
+	return code[:5] + "-" + code[5:]
+}
+
+// NormalizeRecoveryCode makes recovery codes comparable however the user typed them.
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
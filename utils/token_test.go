@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"as issued", "abcde-fghij", "abcdefghij"},
+		{"without dash", "abcdefghij", "abcdefghij"},
+		{"uppercase", "ABCDE-FGHIJ", "abcdefghij"},
+		{"surrounding space", "  abcde-fghij\n", "abcdefghij"},
+		{"extra dashes", "ab-cde-fgh-ij", "abcdefghij"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeRecoveryCode(tt.code); got != tt.want {
+				t.Errorf("NormalizeRecoveryCode(%q) = %q; want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+
+	for range 100 {
+		code := GenerateRecoveryCode()
+		if !format.MatchString(code) {
+			t.Fatalf("GenerateRecoveryCode() = %q; want the form xxxxx-xxxxx", code)
+		}
+	}
+}
+
+func TestRecoveryCodeHash(t *testing.T) {
+	code := GenerateRecoveryCode()
+	stored := HashToken(NormalizeRecoveryCode(code))
+
+	tests := []struct {
+		name  string
+		typed string
+		match bool
+	}{
+		{"as issued", code, true},
+		{"uppercase", "  " + strings.ToUpper(code) + " ", true},
+		{"without dash", NormalizeRecoveryCode(code), true},
+		{"different code", "aaaaa-aaaaa", code == "aaaaa-aaaaa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bytes.Equal(HashToken(NormalizeRecoveryCode(tt.typed)), stored)
+			if got != tt.match {
+				t.Errorf("hash of %q matches %q = %t; want %t", tt.typed, code, got, tt.match)
+			}
+		})
+	}
+}